@@ -3,13 +3,17 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
+	_ "time/tzdata" // Data zona waktu ikut di-embed (untuk file kalender)
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	"weddingpress_backend/internal/config" // Import config
 	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/handlers"
 	"weddingpress_backend/internal/routes" // Import routes
+	"weddingpress_backend/internal/storage"
 )
 
 func init() {
@@ -22,19 +26,40 @@ func init() {
 	// 2. Init Database
 	db.ConnectDatabase()
 	db.RunMigrations()
+
+	// 3. Init Storage (driver dari STORAGE_DRIVER)
+	if err := storage.Init(); err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+	log.Printf("Using %s storage", storage.Active.Name())
 }
 
 func main() {
-	// 3. Init Gin Router
+	// 4. Init Gin Router
 	r := gin.Default()
 
-	// 4. Terapkan Middleware CORS *sebelum* rute
+	// Header X-Forwarded-For hanya dipercaya dari proxy di TRUSTED_PROXIES (daftar IP/CIDR
+	// dipisah koma); tanpa itu c.ClientIP() memakai alamat koneksi langsung
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
+	// 5. Terapkan Middleware CORS *sebelum* rute
 	r.Use(config.CORSMiddleware())
 
-	// 5. Setup Rute (dari file routes.go)
+	// 6. Setup Rute (dari file routes.go)
 	routes.SetupRoutes(r)
 
-	// 6. Run Server
+	// Sesi upload bertahap yang kedaluwarsa dibersihkan berkala
+	handlers.StartUploadSessionCleanup()
+
+	// 7. Run Server
 	port := os.Getenv("SERVER_PORT")
 	if port == "" {
 		port = "8080" // Default port
@@ -1,11 +1,14 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"weddingpress_backend/internal/models" // Import models kita
+	"weddingpress_backend/internal/services"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -15,14 +18,22 @@ import (
 var DB *gorm.DB
 
 func ConnectDatabase() {
+	// Zona waktu sesi database. Waktu acara disimpan sebagai timestamptz (absolut)
+	// dengan zona waktu per acara, jadi sesi cukup memakai UTC.
+	dbTimezone := os.Getenv("DB_TIMEZONE")
+	if dbTimezone == "" {
+		dbTimezone = "UTC"
+	}
+
 	// Membangun Data Source Name (DSN) dari .env
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Jakarta",
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_USER"),
 		os.Getenv("DB_PASS"),
 		os.Getenv("DB_NAME"),
 		os.Getenv("DB_PORT"),
 		os.Getenv("SSL_MODE"),
+		dbTimezone,
 	)
 
 	// Membuka koneksi ke database
@@ -48,15 +59,191 @@ func RunMigrations() {
 		&models.GroomBride{},
 		&models.Event{},
 		&models.Story{},
+		&models.GalleryAlbum{},
 		&models.Gallery{},
+		&models.MediaAsset{},
+		&models.MusicTrack{},
+		&models.GuestPhoto{},
+		&models.UploadSession{},
+		&models.GuestGroup{},
 		&models.Guest{},
+		&models.GuestMember{},
 		&models.GuestBook{},
+		&models.GuestBookModerationLog{},
+		&models.GuestBookReply{},
 		&models.GiftAccount{}, // <-- TAMBAHKAN INI
+		&models.RSVPQuestion{},
+		&models.RSVPAnswer{},
+		&models.SeatingTable{},
+		&models.SeatAssignment{},
 	)
 
 	if err != nil {
 		log.Fatal("Failed to run migrations!", err)
 	}
 
+	if err := backfillGuestGroups(); err != nil {
+		log.Fatal("Failed to migrate guest groups!", err)
+	}
+	if err := uniqueGuestGroupNames(); err != nil {
+		log.Fatal("Failed to migrate guest group names!", err)
+	}
+	if err := backfillEventTimestamps(); err != nil {
+		log.Fatal("Failed to migrate event times!", err)
+	}
+
+	// Tamu yang sudah RSVP sebelum ada pilihan virtual: hadir langsung atau tidak hadir
+	if err := DB.Exec(`UPDATE guests SET attendance_mode = CASE WHEN total_attendance > 0 THEN ? ELSE ? END
+		WHERE is_rsvp = true AND (attendance_mode IS NULL OR attendance_mode = '')`,
+		models.AttendanceInPerson, models.AttendanceDeclined).Error; err != nil {
+		log.Fatal("Failed to migrate attendance mode!", err)
+	}
+
+	// Dulu satu tamu hanya boleh satu ucapan (unique index), kini boleh beberapa
+	if err := DB.Exec("DROP INDEX IF EXISTS idx_guest_books_guest_id").Error; err != nil {
+		log.Fatal("Failed to drop guestbook unique index!", err)
+	}
+
+	// Satu penempatan per tamu (atau per anggota) per acara. Penempatan ganda dari masa
+	// sebelum ada index ini dibuang dulu (yang paling awal dipertahankan).
+	if err := DB.Exec(`DELETE FROM seat_assignments a USING seat_assignments b
+		WHERE a.event_id = b.event_id AND a.guest_id = b.guest_id
+		AND COALESCE(a.guest_member_id, 0) = COALESCE(b.guest_member_id, 0) AND a.id > b.id`).Error; err != nil {
+		log.Fatal("Failed to remove duplicate seat assignments!", err)
+	}
+	if err := DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_seat_assignments_event_guest_member
+		ON seat_assignments (event_id, guest_id, COALESCE(guest_member_id, 0))`).Error; err != nil {
+		log.Fatal("Failed to create seat assignment unique index!", err)
+	}
+
 	log.Println("Database migrations successful.")
 }
+
+// backfillGuestGroups membuat GuestGroup dari nilai Guest.Group (teks bebas) lama
+// dan mengisi group_id tamu yang belum terhubung ke grup.
+// caseDuplicateGuestGroups memetakan grup yang namanya hanya beda huruf besar/kecil dari
+// grup lain di wedding yang sama (dup) ke grup tertua dengan nama itu (keep)
+const caseDuplicateGuestGroups = `WITH m AS (
+	SELECT g.id AS dup, k.keep FROM guest_groups g
+	JOIN (SELECT wedding_id, LOWER(name) AS lname, MIN(id) AS keep FROM guest_groups
+		GROUP BY wedding_id, LOWER(name) HAVING COUNT(*) > 1) k
+	ON g.wedding_id = k.wedding_id AND LOWER(g.name) = k.lname AND g.id <> k.keep)
+`
+
+// uniqueGuestGroupNames menggabungkan grup yang namanya hanya beda huruf besar/kecil (seperti
+// MergeGroup: tamu & undangan acara pindah ke grup tertua), lalu mengganti unique index nama
+// grup yang peka huruf besar/kecil dengan index (wedding_id, LOWER(name))
+func uniqueGuestGroupNames() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			caseDuplicateGuestGroups + `UPDATE guests SET group_id = m.keep, "group" = kg.name
+				FROM m JOIN guest_groups kg ON kg.id = m.keep WHERE guests.group_id = m.dup`,
+			caseDuplicateGuestGroups + `INSERT INTO event_audience_groups (event_id, guest_group_id)
+				SELECT ea.event_id, m.keep FROM event_audience_groups ea JOIN m ON ea.guest_group_id = m.dup
+				ON CONFLICT DO NOTHING`,
+			caseDuplicateGuestGroups + `DELETE FROM event_audience_groups WHERE guest_group_id IN (SELECT dup FROM m)`,
+			caseDuplicateGuestGroups + `DELETE FROM guest_group_events WHERE guest_group_id IN (SELECT dup FROM m)`,
+			caseDuplicateGuestGroups + `DELETE FROM guest_groups WHERE id IN (SELECT dup FROM m)`,
+			`DROP INDEX IF EXISTS idx_guest_group_wedding_name`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_guest_group_wedding_lower_name ON guest_groups (wedding_id, LOWER(name))`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func backfillGuestGroups() error {
+	type legacyGroup struct {
+		WeddingID uint
+		Name      string
+	}
+
+	var legacy []legacyGroup
+	err := DB.Model(&models.Guest{}).
+		Select("DISTINCT wedding_id, TRIM(\"group\") AS name").
+		Where("group_id IS NULL AND TRIM(COALESCE(\"group\", '')) <> ''").
+		Scan(&legacy).Error
+	if err != nil {
+		return err
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, lg := range legacy {
+			// Nama yang hanya beda huruf besar/kecil dianggap grup yang sama
+			var group models.GuestGroup
+			err := tx.Where("wedding_id = ? AND LOWER(name) = LOWER(?)", lg.WeddingID, lg.Name).First(&group).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				group = models.GuestGroup{WeddingID: lg.WeddingID, Name: lg.Name}
+				err = tx.Create(&group).Error
+			}
+			if err != nil {
+				return err
+			}
+
+			err = tx.Model(&models.Guest{}).
+				Where("wedding_id = ? AND group_id IS NULL AND LOWER(TRIM(\"group\")) = LOWER(?)", lg.WeddingID, lg.Name).
+				Updates(map[string]interface{}{"group_id": group.ID, "group": group.Name}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(legacy) > 0 {
+			log.Printf("Migrated %d legacy guest groups.", len(legacy))
+		}
+		return nil
+	})
+}
+
+// backfillEventTimestamps mengisi start_at/end_at/timezone untuk acara lama
+// dari kolom date + start_time/end_time ("HH:MM"), yang dulu selalu berarti WIB
+// (atau zona waktu wedding jika sudah diatur).
+func backfillEventTimestamps() error {
+	type legacyEvent struct {
+		ID        uint
+		Date      time.Time
+		StartTime string
+		EndTime   string
+		Timezone  string // Zona waktu wedding
+	}
+
+	var legacy []legacyEvent
+	err := DB.Table("events").
+		Select("events.id, events.date, events.start_time, events.end_time, weddings.timezone").
+		Joins("JOIN weddings ON weddings.id = events.wedding_id").
+		Where("events.start_at IS NULL").
+		Scan(&legacy).Error
+	if err != nil {
+		return err
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, e := range legacy {
+			loc, err := services.LoadTimezone(e.Timezone)
+			if err != nil {
+				return fmt.Errorf("event %d: %w", e.ID, err)
+			}
+
+			start := services.CombineDateAndClock(e.Date, e.StartTime, loc)
+			updates := map[string]interface{}{"start_at": start, "timezone": loc.String()}
+			if _, _, ok := services.ParseClock(e.EndTime); ok {
+				end := services.CombineDateAndClock(e.Date, e.EndTime, loc)
+				if !end.After(start) {
+					end = end.AddDate(0, 0, 1) // Acara lama yang melewati tengah malam
+				}
+				updates["end_at"] = end
+			}
+
+			if err := tx.Table("events").Where("id = ?", e.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if len(legacy) > 0 {
+			log.Printf("Migrated %d legacy event times.", len(legacy))
+		}
+		return nil
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log" // <-- TAMBAHAN (Untuk Bug Fix)
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"weddingpress_backend/internal/db"
@@ -84,6 +86,8 @@ type UpdateWeddingInput struct {
 	Template string `json:"template"`
 	// ----------------
 
+	Timezone string `json:"timezone"` // Zona waktu IANA; kosong = tidak diubah
+
 	// --- TAMBAHKAN FIELD KUSTOMISASI DI SINI ---
 	ShowEvents    bool `json:"show_events"`
 	ShowStory     bool `json:"show_story"`
@@ -121,6 +125,16 @@ func UpdateMyWedding(c *gin.Context) {
 		"ShowEvents", "ShowStory", "ShowGallery", "ShowGifts", "ShowGuestBook",
 	}
 
+	input.Timezone = strings.TrimSpace(input.Timezone)
+	if input.Timezone != "" {
+		if _, err := time.LoadLocation(input.Timezone); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid timezone %q", input.Timezone)})
+			return
+		}
+		fieldsToUpdate = append(fieldsToUpdate, "Timezone")
+	}
+
 	// 1. Update data Wedding
 	wedding := models.Wedding{ID: weddingID}
 	if err := tx.Model(&wedding).Select(fieldsToUpdate).Updates(models.Wedding{
@@ -129,6 +143,7 @@ func UpdateMyWedding(c *gin.Context) {
 		MusicURL:      input.MusicURL,
 		ThemeColor:    input.ThemeColor,
 		Template:      input.Template, // <-- Simpan input template
+		Timezone:      input.Timezone,
 
 		ShowEvents:    input.ShowEvents,
 		ShowStory:     input.ShowStory,
@@ -173,8 +188,16 @@ func GetGallery(c *gin.Context) {
 		return
 	}
 
+	query := db.DB.Where("wedding_id = ?", weddingID)
+	// Filter opsional: ?album_id=<id> atau ?album_id=none (tanpa album)
+	if albumID := c.Query("album_id"); albumID == "none" {
+		query = query.Where("album_id IS NULL")
+	} else if albumID != "" {
+		query = query.Where("album_id = ?", albumID)
+	}
+
 	var galleries []models.Gallery
-	if err := db.DB.Where("wedding_id = ?", weddingID).Find(&galleries).Error; err != nil {
+	if err := query.Order("album_id ASC NULLS FIRST, \"order\" ASC, id ASC").Find(&galleries).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gallery"})
 		return
 	}
@@ -186,6 +209,14 @@ type CreateGalleryInput struct {
 	FileURL  string `json:"file_url" binding:"required"`
 	FileType string `json:"file_type" binding:"required"` // "image" atau "video"
 	Caption  string `json:"caption"`
+	AlbumID  *uint  `json:"album_id"`
+	IsCover  bool   `json:"is_cover"`
+
+	// Varian gambar dari respons endpoint upload (opsional)
+	ThumbURL  string `json:"thumb_url"`
+	MediumURL string `json:"medium_url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
 }
 
 func CreateGalleryItem(c *gin.Context) {
@@ -201,20 +232,110 @@ func CreateGalleryItem(c *gin.Context) {
 		return
 	}
 
+	if err := checkGalleryAlbum(input.AlbumID, weddingID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	galleryItem := models.Gallery{
 		WeddingID: weddingID,
 		FileURL:   input.FileURL,
 		FileType:  input.FileType,
 		Caption:   input.Caption,
-	}
-
-	if err := db.DB.Create(&galleryItem).Error; err != nil {
+		ThumbURL:  input.ThumbURL,
+		MediumURL: input.MediumURL,
+		Width:     input.Width,
+		Height:    input.Height,
+		AlbumID:   input.AlbumID,
+		IsCover:   input.IsCover,
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		galleryItem.Order = nextOrder(tx, "galleries", galleryAlbumScope(weddingID, input.AlbumID)) // Item baru di paling akhir album
+		if err := tx.Create(&galleryItem).Error; err != nil {
+			return err
+		}
+		if galleryItem.IsCover {
+			return clearOtherCovers(tx, galleryItem)
+		}
+		return nil
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save gallery item"})
 		return
 	}
 	c.JSON(http.StatusCreated, galleryItem)
 }
 
+type UpdateGalleryInput struct {
+	FileType string `json:"file_type" binding:"required"` // "image" atau "video"
+	Caption  string `json:"caption"`
+	AlbumID  *uint  `json:"album_id"` // null = tanpa album
+	IsCover  *bool  `json:"is_cover"` // Kosong = tetap (dilepas jika pindah album)
+}
+
+// UpdateGalleryItem memperbarui caption, album & tipe file item galeri.
+// Urutan diubah lewat endpoint reorder; item yang pindah album ditaruh paling akhir.
+func UpdateGalleryItem(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input UpdateGalleryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.FileType = strings.ToLower(strings.TrimSpace(input.FileType))
+	if input.FileType != mediaTypeImage && input.FileType != mediaTypeVideo {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file_type must be 'image' or 'video'"})
+		return
+	}
+	if err := checkGalleryAlbum(input.AlbumID, weddingID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var galleryItem models.Gallery
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&galleryItem).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gallery item not found"})
+		return
+	}
+
+	albumChanged := (galleryItem.AlbumID == nil) != (input.AlbumID == nil) ||
+		(galleryItem.AlbumID != nil && *galleryItem.AlbumID != *input.AlbumID)
+
+	galleryItem.FileType = input.FileType
+	galleryItem.Caption = strings.TrimSpace(input.Caption)
+	galleryItem.AlbumID = input.AlbumID
+	switch {
+	case input.IsCover != nil:
+		galleryItem.IsCover = *input.IsCover
+	case albumChanged:
+		galleryItem.IsCover = false // Sampul album lama tidak otomatis jadi sampul album baru
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if albumChanged {
+			galleryItem.Order = nextOrder(tx, "galleries", galleryAlbumScope(weddingID, input.AlbumID))
+		}
+		if err := tx.Select("FileType", "Caption", "AlbumID", "IsCover", "Order").Save(&galleryItem).Error; err != nil {
+			return err
+		}
+		if galleryItem.IsCover {
+			return clearOtherCovers(tx, galleryItem)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update gallery item"})
+		return
+	}
+	c.JSON(http.StatusOK, galleryItem)
+}
+
 func DeleteGalleryItem(c *gin.Context) {
 	weddingID, err := getWeddingIDFromAuth(c)
 	if err != nil {
@@ -235,6 +356,8 @@ func DeleteGalleryItem(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete item"})
 		return
 	}
+	// File di storage ikut dihapus jika tidak dipakai record lain
+	releaseMediaAssets(weddingID, galleryItem.FileURL, galleryItem.MediumURL, galleryItem.ThumbURL)
 	c.JSON(http.StatusOK, gin.H{"message": "Gallery item deleted"})
 }
 
@@ -248,7 +371,12 @@ func GetEvents(c *gin.Context) {
 	}
 
 	var events []models.Event
-	if err := db.DB.Where("wedding_id = ?", weddingID).Order("date ASC").Find(&events).Error; err != nil {
+	if err := db.DB.
+		Preload("AudienceGroups").
+		Preload("AudienceGuests", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name", "slug", "group_id", "group") // Cukup data ringkas tamu
+		}).
+		Where("wedding_id = ?", weddingID).Order("start_at ASC").Find(&events).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
 		return
 	}
@@ -257,12 +385,83 @@ func GetEvents(c *gin.Context) {
 }
 
 type EventInput struct {
-	Name      string    `json:"name" binding:"required"`
-	Date      time.Time `json:"date" binding:"required"`
-	StartTime string    `json:"start_time"`
-	EndTime   string    `json:"end_time"`
-	Address   string    `json:"address"`
-	MapsURL   string    `json:"maps_url"`
+	Name string `json:"name" binding:"required"`
+
+	// Waktu acara: RFC3339 (dengan offset) atau waktu lokal "YYYY-MM-DDTHH:MM" di zona waktu acara
+	StartAt  string `json:"start_at"`
+	EndAt    string `json:"end_at"`
+	Timezone string `json:"timezone"` // Zona waktu IANA, default mengikuti wedding
+
+	// Format lama, dipakai jika start_at kosong
+	Date      string `json:"date"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+
+	Address string `json:"address"`
+	MapsURL string `json:"maps_url"`
+
+	// Lokasi: koordinat diambil dari maps_url jika tidak diisi
+	VenueName    string   `json:"venue_name"`
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	ParkingNotes string   `json:"parking_notes"`
+
+	// Live streaming (opsional)
+	LiveStreamURL           string `json:"live_stream_url"`
+	LiveStreamPlatform      string `json:"live_stream_platform"` // Terdeteksi dari URL jika kosong
+	LiveStreamStartAt       string `json:"live_stream_start_at"` // Default: waktu mulai acara
+	LiveStreamNote          string `json:"live_stream_note"`
+	LiveStreamRevealMinutes *int   `json:"live_stream_reveal_minutes"` // Default 60
+
+	// Aturan audiens: "all" (default), "groups" atau "guests"
+	Audience         string `json:"audience"`
+	AudienceGroupIDs []uint `json:"audience_group_ids"`
+	AudienceGuestIDs []uint `json:"audience_guest_ids"`
+}
+
+// resolveEventAudience memvalidasi aturan audiens dan memuat grup/tamu yang dipilih
+func resolveEventAudience(weddingID uint, input *EventInput) ([]models.GuestGroup, []models.Guest, error) {
+	groups := []models.GuestGroup{}
+	guests := []models.Guest{}
+
+	switch input.Audience {
+	case "", models.EventAudienceAll:
+		input.Audience = models.EventAudienceAll
+		return groups, guests, nil
+	case models.EventAudienceGroups:
+		ids := uniqueIDs(input.AudienceGroupIDs)
+		if len(ids) == 0 {
+			return nil, nil, errors.New("audience_group_ids is required when audience is 'groups'")
+		}
+		if err := db.DB.Where("id IN ? AND wedding_id = ?", ids, weddingID).Find(&groups).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(groups) != len(ids) {
+			return nil, nil, errors.New("some groups were not found")
+		}
+	case models.EventAudienceGuests:
+		ids := uniqueIDs(input.AudienceGuestIDs)
+		if len(ids) == 0 {
+			return nil, nil, errors.New("audience_guest_ids is required when audience is 'guests'")
+		}
+		if err := db.DB.Where("id IN ? AND wedding_id = ?", ids, weddingID).Find(&guests).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(guests) != len(ids) {
+			return nil, nil, errors.New("some guests were not found")
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid audience %q (use 'all', 'groups' or 'guests')", input.Audience)
+	}
+	return groups, guests, nil
+}
+
+// saveEventAudience mengganti daftar grup & tamu yang diundang ke acara
+func saveEventAudience(tx *gorm.DB, event *models.Event, groups []models.GuestGroup, guests []models.Guest) error {
+	if err := tx.Model(event).Association("AudienceGroups").Replace(groups); err != nil {
+		return err
+	}
+	return tx.Model(event).Association("AudienceGuests").Replace(guests)
 }
 
 func CreateEvent(c *gin.Context) {
@@ -278,17 +477,48 @@ func CreateEvent(c *gin.Context) {
 		return
 	}
 
-	event := models.Event{
-		WeddingID: weddingID,
-		Name:      input.Name,
-		Date:      input.Date,
-		StartTime: input.StartTime,
-		EndTime:   input.EndTime,
-		Address:   input.Address,
-		MapsURL:   input.MapsURL,
+	times, err := input.resolveTimes(weddingID, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.normalizeLocation(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stream, err := input.resolveLiveStream(times.Loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	audienceGroups, audienceGuests, err := resolveEventAudience(weddingID, &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := db.DB.Create(&event).Error; err != nil {
+	event := models.Event{
+		WeddingID:    weddingID,
+		Name:         input.Name,
+		Address:      input.Address,
+		MapsURL:      input.MapsURL,
+		VenueName:    input.VenueName,
+		Latitude:     input.Latitude,
+		Longitude:    input.Longitude,
+		ParkingNotes: input.ParkingNotes,
+		Audience:     input.Audience,
+	}
+	setEventTimes(&event, times)
+	stream.apply(&event)
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		return saveEventAudience(tx, &event, audienceGroups, audienceGuests)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
 		return
 	}
@@ -316,15 +546,47 @@ func UpdateEvent(c *gin.Context) {
 		return
 	}
 
+	// Tanpa timezone di input, acara tetap di zona waktunya sendiri
+	times, err := input.resolveTimes(weddingID, event.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.normalizeLocation(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stream, err := input.resolveLiveStream(times.Loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	audienceGroups, audienceGuests, err := resolveEventAudience(weddingID, &input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Update model
 	event.Name = input.Name
-	event.Date = input.Date
-	event.StartTime = input.StartTime
-	event.EndTime = input.EndTime
+	setEventTimes(&event, times)
 	event.Address = input.Address
 	event.MapsURL = input.MapsURL
-
-	if err := db.DB.Save(&event).Error; err != nil {
+	event.VenueName = input.VenueName
+	event.Latitude = input.Latitude
+	event.Longitude = input.Longitude
+	event.ParkingNotes = input.ParkingNotes
+	stream.apply(&event)
+	event.Audience = input.Audience
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&event).Error; err != nil {
+			return err
+		}
+		return saveEventAudience(tx, &event, audienceGroups, audienceGuests)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
 		return
 	}
@@ -345,7 +607,23 @@ func DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Delete(&event).Error; err != nil {
+	// Hapus juga meja & penempatan tempat duduk untuk acara ini
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.SeatAssignment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.SeatingTable{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM guest_group_events WHERE event_id = ?", event.ID).Error; err != nil {
+			return err
+		}
+		if err := saveEventAudience(tx, &event, nil, nil); err != nil {
+			return err
+		}
+		return tx.Delete(&event).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
 		return
 	}
@@ -363,7 +641,7 @@ func GetStories(c *gin.Context) {
 
 	var stories []models.Story
 	// Perhatikan: "order" adalah keyword SQL, perlu di-escape
-	if err := db.DB.Where("wedding_id = ?", weddingID).Order("\"order\" ASC").Find(&stories).Error; err != nil {
+	if err := db.DB.Where("wedding_id = ?", weddingID).Order("\"order\" ASC, id ASC").Find(&stories).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stories"})
 		return
 	}
@@ -377,17 +655,37 @@ func GetStories(c *gin.Context) {
 type StoryInput struct {
 	Title       string `json:"title" binding:"required"`
 	Date        string `json:"date" binding:"required"` // <-- UBAH TIPE MENJADI STRING
-	Description string `json:"description"`
+	Description string `json:"description"`             // Markdown
 	Order       int    `json:"order"`
+	MediaURL    string `json:"media_url"`  // Foto/video dari endpoint upload
+	MediaType   string `json:"media_type"` // "image" atau "video" (ditebak dari URL jika kosong)
+	Location    string `json:"location"`
 }
 
-// Fungsi helper untuk parsing tanggal
-func parseDateString(dateStr string) (time.Time, error) {
+// normalize menyanitasi deskripsi Markdown dan memvalidasi media story
+// (currentMediaURL: media story yang sudah tersimpan, kosong untuk story baru)
+func (in *StoryInput) normalize(weddingID uint, currentMediaURL string) error {
+	in.Title = strings.TrimSpace(in.Title)
+	if in.Title == "" {
+		return errors.New("title is required")
+	}
+	in.Location = strings.TrimSpace(in.Location)
+	description, err := services.SanitizeMarkdown(in.Description)
+	if err != nil {
+		return fmt.Errorf("description: %w", err)
+	}
+	in.Description = description
+	return validateWeddingMedia(&in.MediaURL, &in.MediaType, weddingID, currentMediaURL)
+}
+
+// Fungsi helper untuk parsing tanggal (hasilnya tanggal saja, tengah malam UTC)
+func parseDateString(dateStr string, loc *time.Location) (time.Time, error) {
 	// Coba parsing format RFC3339 (jika frontend kirim timestamp penuh)
 	// format: "2025-11-05T00:00:00+07:00" atau "2025-11-04T17:00:00Z"
+	// Timestamp diubah ke zona waktu wedding dulu, jadi "2025-11-04T17:00:00Z" tetap 5 Nov di WIB.
 	parsedDate, err := time.Parse(time.RFC3339, dateStr)
 	if err == nil {
-		return parsedDate, nil
+		return services.DateOnly(parsedDate.In(loc)), nil
 	}
 
 	// Jika gagal, coba parsing format YYYY-MM-DD
@@ -417,9 +715,18 @@ func CreateStory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := input.normalize(weddingID, ""); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// --- TAMBAHKAN BLOK PARSING INI ---
-	parsedDate, err := parseDateString(input.Date)
+	loc, err := weddingLocation(weddingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+	parsedDate, err := parseDateString(input.Date, loc)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -432,6 +739,9 @@ func CreateStory(c *gin.Context) {
 		Date:        parsedDate, // <-- Gunakan tanggal yang sudah di-parse
 		Description: input.Description,
 		Order:       input.Order,
+		MediaURL:    input.MediaURL,
+		MediaType:   input.MediaType,
+		Location:    input.Location,
 	}
 
 	if err := db.DB.Create(&story).Error; err != nil {
@@ -454,25 +764,36 @@ func UpdateStory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var story models.Story
+	if err := db.DB.Where("id = ? AND wedding_id = ?", storyID, weddingID).First(&story).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Story not found"})
+		return
+	}
+	if err := input.normalize(weddingID, story.MediaURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// --- TAMBAHKAN BLOK PARSING INI ---
-	parsedDate, err := parseDateString(input.Date)
+	loc, err := weddingLocation(weddingID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
 		return
 	}
-	// --- SELESAI BLOK PARSING ---
-
-	var story models.Story
-	if err := db.DB.Where("id = ? AND wedding_id = ?", storyID, weddingID).First(&story).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Story not found"})
+	parsedDate, err := parseDateString(input.Date, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// --- SELESAI BLOK PARSING ---
 
 	story.Title = input.Title
 	story.Date = parsedDate // <-- Gunakan tanggal yang sudah di-parse
 	story.Description = input.Description
 	story.Order = input.Order
+	story.MediaURL = input.MediaURL
+	story.MediaType = input.MediaType
+	story.Location = input.Location
 
 	if err := db.DB.Save(&story).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update story"})
@@ -518,6 +839,7 @@ func GetGuests(c *gin.Context) {
 	// 1. Ambil query parameter dari URL
 	search := c.Query("search")
 	group := c.Query("group")
+	groupID := c.Query("group_id")
 
 	// 2. Buat query GORM dinamis
 	// Kita mulai dengan model dan filter wedding_id
@@ -534,10 +856,13 @@ func GetGuests(c *gin.Context) {
 	if group != "" {
 		query = query.Where("\"group\" = ?", group) // "group" perlu di-escape
 	}
+	if groupID != "" {
+		query = query.Where("group_id = ?", groupID)
+	}
 
 	// 5. Eksekusi query yang sudah difilter
 	var guests []models.Guest
-	if err := query.Order("created_at DESC").Find(&guests).Error; err != nil {
+	if err := query.Preload("Members").Order("created_at DESC").Find(&guests).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guests"})
 		return
 	}
@@ -549,7 +874,8 @@ func GetGuests(c *gin.Context) {
 
 // === TAMBAHKAN HANDLER BARU DI BAWAH INI ===
 
-// GetGuestGroups mengembalikan daftar unik semua grup tamu
+// GetGuestGroups mengembalikan daftar nama semua grup tamu
+// (Untuk data lengkap grup beserta pengaturannya, gunakan GetGroups)
 func GetGuestGroups(c *gin.Context) {
 	weddingID, err := getWeddingIDFromAuth(c)
 	if err != nil {
@@ -557,16 +883,11 @@ func GetGuestGroups(c *gin.Context) {
 		return
 	}
 
-	var groups []string
-
-	// Query ini akan:
-	// 1. Memfilter berdasarkan weddingID
-	// 2. Memilih "group" yang unik (Distinct)
-	// 3. Mengabaikan nilai NULL atau string kosong
-	err = db.DB.Model(&models.Guest{}).
-		Where("wedding_id = ? AND \"group\" IS NOT NULL AND \"group\" != ''", weddingID).
-		Distinct().
-		Pluck("\"group\"", &groups).Error // Pluck mengambil satu kolom ke slice
+	groups := []string{}
+	err = db.DB.Model(&models.GuestGroup{}).
+		Where("wedding_id = ?", weddingID).
+		Order("name ASC").
+		Pluck("name", &groups).Error // Pluck mengambil satu kolom ke slice
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
@@ -577,8 +898,18 @@ func GetGuestGroups(c *gin.Context) {
 }
 
 type GuestInput struct {
-	Name  string `json:"name" binding:"required"`
-	Group string `json:"group"`
+	Name            string `json:"name" binding:"required"`
+	GroupID         *uint  `json:"group_id"`         // Diutamakan daripada nama grup
+	Group           string `json:"group"`            // Nama grup; dibuat otomatis jika belum ada
+	AttendanceQuota *int   `json:"attendance_quota"` // Opsional, default 2
+}
+
+// validateAttendanceQuota memastikan kuota (jika dikirim) minimal 1 orang
+func validateAttendanceQuota(quota *int) error {
+	if quota != nil && *quota < 1 {
+		return errors.New("attendance_quota must be at least 1")
+	}
+	return nil
 }
 
 func CreateGuest(c *gin.Context) {
@@ -593,6 +924,10 @@ func CreateGuest(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateAttendanceQuota(input.AttendanceQuota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Buat slug unik
 	baseSlug := services.Slugify(input.Name)
@@ -609,11 +944,23 @@ func CreateGuest(c *gin.Context) {
 		slug = baseSlug + "-" + services.RandomString(4)
 	}
 
+	group, err := resolveGuestGroup(db.DB, weddingID, input.GroupID, input.Group)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	guest := models.Guest{
 		WeddingID: weddingID,
 		Name:      input.Name,
 		Slug:      slug,
-		Group:     input.Group,
+	}
+	applyGuestGroup(&guest, group)
+	// Kuota: input eksplisit > default grup > default model
+	if input.AttendanceQuota != nil {
+		guest.AttendanceQuota = *input.AttendanceQuota
+	} else if group != nil {
+		guest.AttendanceQuota = group.DefaultQuota
 	}
 
 	if err := db.DB.Create(&guest).Error; err != nil {
@@ -637,6 +984,10 @@ func UpdateGuest(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateAttendanceQuota(input.AttendanceQuota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	var guest models.Guest
 	if err := db.DB.Where("id = ? AND wedding_id = ?", guestID, weddingID).First(&guest).Error; err != nil {
@@ -644,11 +995,37 @@ func UpdateGuest(c *gin.Context) {
 		return
 	}
 
-	guest.Name = input.Name
-	guest.Group = input.Group
-	// (Note: Slug tidak di-update untuk menjaga stabilitas URL)
+	group, err := resolveGuestGroup(db.DB, weddingID, input.GroupID, input.Group)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Kuota tidak boleh lebih kecil dari jumlah anggota rombongan yang sudah terdaftar
+	var members int64
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		locked, count, err := lockGuestMembers(tx, guest.ID)
+		if err != nil {
+			return err
+		}
+		guest, members = locked, count
 
-	if err := db.DB.Save(&guest).Error; err != nil {
+		guest.Name = input.Name
+		applyGuestGroup(&guest, group)
+		if input.AttendanceQuota != nil {
+			if int64(*input.AttendanceQuota) < members {
+				return errGuestQuotaReached
+			}
+			guest.AttendanceQuota = *input.AttendanceQuota
+		}
+		// (Note: Slug tidak di-update untuk menjaga stabilitas URL)
+		return tx.Save(&guest).Error
+	})
+	if errors.Is(err, errGuestQuotaReached) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("attendance_quota cannot be less than the %d registered members", members)})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update guest"})
 		return
 	}
@@ -671,24 +1048,38 @@ func DeleteGuest(c *gin.Context) {
 
 	// Hapus juga guestbook terkait (opsional, tergantung GORM/DB constraint)
 	// GORM akan error jika ada foreign key constraint, jadi lebih baik hapus manual
+	deleteGuestBookReplies(db.DB, db.DB.Model(&models.GuestBook{}).Select("id").Where("guest_id = ?", guest.ID))
 	db.DB.Where("guest_id = ?", guest.ID).Delete(&models.GuestBook{})
+	db.DB.Where("guest_id = ?", guest.ID).Delete(&models.GuestMember{})
+	db.DB.Where("guest_id = ?", guest.ID).Delete(&models.SeatAssignment{})
+	var photoURLs []string
+	db.DB.Model(&models.GuestPhoto{}).Where("guest_id = ? AND status = ?", guest.ID, models.GuestPhotoPending).Pluck("file_url", &photoURLs)
+	db.DB.Where("guest_id = ? AND status = ?", guest.ID, models.GuestPhotoPending).Delete(&models.GuestPhoto{})
+	db.DB.Exec("DELETE FROM event_audience_guests WHERE guest_id = ?", guest.ID)
 
 	if err := db.DB.Delete(&guest).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete guest"})
 		return
 	}
+	// File foto kiriman tamu yang belum di-approve ikut dihapus dari storage
+	releaseMediaAssets(weddingID, photoURLs...)
 	c.JSON(http.StatusOK, gin.H{"message": "Guest deleted"})
 }
 
 // --- GuestBook Admin Handlers ---
 
 type AdminGuestBookResponse struct {
-	ID        uint      `json:"id"`
-	GuestID   uint      `json:"guest_id"`
-	GuestName string    `json:"guest_name"`
-	Message   string    `json:"message"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           uint       `json:"id"`
+	GuestID      uint       `json:"guest_id"`
+	GuestName    string     `json:"guest_name"`
+	Message      string     `json:"message"`
+	Status       string     `json:"status"`
+	Flagged      bool       `json:"flagged"`
+	FlaggedWords string     `json:"flagged_words"`
+	ModeratedAt  *time.Time `json:"moderated_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Replies []models.GuestBookReply `gorm:"-" json:"replies"` // Balasan mempelai
 }
 
 // GetGuestBookAdmin mengambil semua ucapan (pending & approved)
@@ -708,14 +1099,19 @@ func GetGuestBookAdmin(c *gin.Context) {
 
 	// Mulai query
 	query := db.DB.Table("guest_books").
-		Select("guest_books.id, guest_books.guest_id, guests.name as guest_name, guest_books.message, guest_books.status, guest_books.created_at").
+		Select("guest_books.id, guest_books.guest_id, guests.name as guest_name, guest_books.message, guest_books.status, "+
+			"guest_books.flagged, guest_books.flagged_words, guest_books.moderated_at, guest_books.created_at").
 		Joins("JOIN guests ON guests.id = guest_books.guest_id").
 		Where("guests.wedding_id = ?", weddingID)
 
 	// Tambahkan filter status
-	if statusFilter == "pending" || statusFilter == "approved" {
+	if guestBookStatuses[statusFilter] {
 		query = query.Where("guest_books.status = ?", statusFilter)
 	}
+	// ?flagged=true: hanya ucapan yang mengandung kata terlarang
+	if c.Query("flagged") == "true" {
+		query = query.Where("guest_books.flagged = ?", true)
+	}
 
 	// --- BARU: Tambahkan filter pencarian (berdasarkan nama tamu) ---
 	if searchQuery != "" {
@@ -731,11 +1127,25 @@ func GetGuestBookAdmin(c *gin.Context) {
 		return
 	}
 
+	ids := make([]uint, len(results))
+	for i, entry := range results {
+		ids[i] = entry.ID
+	}
+	replies, err := guestBookReplies(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil balasan ucapan"})
+		return
+	}
+	for i := range results {
+		results[i].Replies = replies[results[i].ID]
+	}
+
 	c.JSON(http.StatusOK, results)
 }
 
 type UpdateGuestBookStatusInput struct {
-	Status string `json:"status" binding:"required"` // "pending" atau "approved"
+	Status string `json:"status" binding:"required"` // pending, approved, rejected atau hidden
+	Note   string `json:"note"`                      // Alasan (opsional), disimpan di log moderasi
 }
 
 // UpdateGuestBookStatus mengubah status ucapan (approve/reject)
@@ -754,8 +1164,8 @@ func UpdateGuestBookStatus(c *gin.Context) {
 	}
 
 	// Validasi status
-	if input.Status != "approved" && input.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+	if !guestBookStatuses[input.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status (use pending, approved, rejected or hidden)"})
 		return
 	}
 
@@ -770,8 +1180,23 @@ func UpdateGuestBookStatus(c *gin.Context) {
 		return
 	}
 
+	if guestBook.Status == input.Status {
+		c.JSON(http.StatusOK, gin.H{"message": "Guestbook status updated"})
+		return
+	}
+
+	previous := guestBook.Status
+	now := time.Now()
 	guestBook.Status = input.Status
-	if err := db.DB.Save(&guestBook).Error; err != nil {
+	guestBook.ModeratedAt = &now
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&guestBook).Error; err != nil {
+			return err
+		}
+		return logGuestBookModeration(tx, weddingID, guestBook.ID, currentUserID(c),
+			moderationStatusChanged, previous, input.Status, strings.TrimSpace(input.Note))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
 		return
 	}
@@ -799,7 +1224,16 @@ func DeleteGuestBook(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Delete(&guestBook).Error; err != nil {
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteGuestBookReplies(tx, []uint{guestBook.ID}); err != nil {
+			return err
+		}
+		if err := tx.Delete(&guestBook).Error; err != nil {
+			return err
+		}
+		return logGuestBookModeration(tx, weddingID, guestBook.ID, currentUserID(c), moderationDeleted, guestBook.Status, "", "")
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete guestbook entry"})
 		return
 	}
@@ -839,7 +1273,7 @@ func ImportGuests(c *gin.Context) {
 	}
 
 	// 4. Asumsikan data ada di sheet pertama (default "Sheet1")
-	//    Format: Kolom A = Nama, Kolom B = Grup
+	//    Format: Kolom A = Nama, Kolom B = Grup, Kolom C = Kuota (opsional)
 	rows, err := f.GetRows("Sheet1")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rows from 'Sheet1'"})
@@ -869,6 +1303,16 @@ func ImportGuests(c *gin.Context) {
 		if len(row) > 1 {
 			group = row[1] // Kolom B
 		}
+		quota := 0
+		if len(row) > 2 && strings.TrimSpace(row[2]) != "" {
+			q, err := strconv.Atoi(strings.TrimSpace(row[2])) // Kolom C
+			if err != nil || q < 1 {
+				tx.Rollback()
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid quota on excel row %d (%s)", i+1, row[2])})
+				return
+			}
+			quota = q
+		}
 
 		// Jangan impor jika nama kosong
 		if name == "" {
@@ -891,12 +1335,23 @@ func ImportGuests(c *gin.Context) {
 			slug = baseSlug + "-" + services.RandomString(4)
 		}
 
-		// 8. Buat data Guest
+		// 8. Buat data Guest (grup dicari/dibuat berdasarkan nama)
+		guestGroup, err := resolveGuestGroup(tx, weddingID, nil, group)
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to resolve group on excel row %d (%s)", i+1, group)})
+			return
+		}
 		guest := models.Guest{
 			WeddingID: weddingID,
 			Name:      name,
 			Slug:      slug,
-			Group:     group,
+		}
+		applyGuestGroup(&guest, guestGroup)
+		if quota > 0 {
+			guest.AttendanceQuota = quota
+		} else if guestGroup != nil {
+			guest.AttendanceQuota = guestGroup.DefaultQuota
 		}
 
 		// 9. Simpan ke database (masih dalam transaksi)
@@ -950,13 +1405,48 @@ func DeleteGuestsBulk(c *gin.Context) {
 		}
 	}()
 
-	// 1. Hapus GuestBook terkait terlebih dahulu untuk menghindari error foreign key
+	// 1. Hapus GuestBook terkait (beserta balasannya) terlebih dahulu untuk menghindari error foreign key
+	if err := deleteGuestBookReplies(tx, tx.Model(&models.GuestBook{}).Select("id").Where("guest_id IN ?", input.IDs)); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated guestbook entries"})
+		return
+	}
 	if err := tx.Where("guest_id IN ?", input.IDs).Delete(&models.GuestBook{}).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated guestbook entries"})
 		return
 	}
 
+	// Hapus juga anggota rombongan milik tamu tersebut (hanya milik wedding ini)
+	ownedGuestIDs := tx.Model(&models.Guest{}).Select("id").Where("id IN ? AND wedding_id = ?", input.IDs, weddingID)
+	if err := tx.Where("guest_id IN (?)", ownedGuestIDs).Delete(&models.GuestMember{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated members"})
+		return
+	}
+	if err := tx.Where("guest_id IN (?)", ownedGuestIDs).Delete(&models.SeatAssignment{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated seat assignments"})
+		return
+	}
+	var photoURLs []string
+	if err := tx.Model(&models.GuestPhoto{}).Where("guest_id IN (?) AND status = ?", ownedGuestIDs, models.GuestPhotoPending).
+		Pluck("file_url", &photoURLs).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated guest photos"})
+		return
+	}
+	if err := tx.Where("guest_id IN (?) AND status = ?", ownedGuestIDs, models.GuestPhotoPending).Delete(&models.GuestPhoto{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated guest photos"})
+		return
+	}
+	if err := tx.Exec("DELETE FROM event_audience_guests WHERE guest_id IN (?)", ownedGuestIDs).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated event invitations"})
+		return
+	}
+
 	// 2. Hapus Tamu, pastikan tamu tersebut milik weddingID yang terautentikasi
 	// Ini adalah cek keamanan yang penting
 	result := tx.Where("id IN ? AND wedding_id = ?", input.IDs, weddingID).Delete(&models.Guest{})
@@ -971,6 +1461,8 @@ func DeleteGuestsBulk(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit error"})
 		return
 	}
+	// File foto kiriman tamu yang belum di-approve ikut dihapus dari storage
+	releaseMediaAssets(weddingID, photoURLs...)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Selected guests deleted successfully",
@@ -978,6 +1470,47 @@ func DeleteGuestsBulk(c *gin.Context) {
 	})
 }
 
+// BulkDeleteGallery menghapus banyak item galeri sekaligus
+func BulkDeleteGallery(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input BulkDeleteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Hanya item milik wedding ini yang boleh dihapus (multi-tenancy)
+	var items []models.Gallery
+	if err := db.DB.Where("id IN (?) AND wedding_id = ?", input.IDs, weddingID).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete gallery items"})
+		return
+	}
+	if len(items) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "No gallery items found to delete"})
+		return
+	}
+
+	result := db.DB.Delete(&items)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete gallery items"})
+		return
+	}
+
+	// File di storage ikut dihapus jika tidak dipakai record lain
+	var urls []string
+	for _, item := range items {
+		urls = append(urls, item.FileURL, item.MediumURL, item.ThumbURL)
+	}
+	releaseMediaAssets(weddingID, urls...)
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%d gallery items deleted", result.RowsAffected)})
+}
+
 // BulkDeleteGuestBook menghapus banyak ucapan sekaligus
 func BulkDeleteGuestBook(c *gin.Context) {
 	weddingID, err := getWeddingIDFromAuth(c)
@@ -999,9 +1532,26 @@ func BulkDeleteGuestBook(c *gin.Context) {
 
 	// 2. Lakukan penghapusan pada tabel guest_books:
 	//    Hapus jika ID ada di input.IDs AND guest_id ada di validGuestIDs (multi-tenancy)
+	var entries []models.GuestBook
 	result := db.DB.Where("id IN (?)", input.IDs).
 		Where("guest_id IN (?)", validGuestIDs).
-		Delete(&models.GuestBook{})
+		Find(&entries)
+	if result.Error == nil && len(entries) > 0 {
+		ids := make([]uint, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			if err := deleteGuestBookReplies(tx, ids); err != nil {
+				return err
+			}
+			result = tx.Delete(&entries)
+			return result.Error
+		})
+		if err != nil {
+			result.Error = err
+		}
+	}
 
 	// --- PERBAIKAN SELESAI ---
 
@@ -1017,6 +1567,13 @@ func BulkDeleteGuestBook(c *gin.Context) {
 		return
 	}
 
+	// Catat penghapusan di log moderasi
+	for _, entry := range entries {
+		if err := logGuestBookModeration(db.DB, weddingID, entry.ID, currentUserID(c), moderationDeleted, entry.Status, "", "bulk delete"); err != nil {
+			log.Printf("Failed to log guestbook deletion: %v", err)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%d ucapan Guestbook berhasil dihapus", result.RowsAffected)})
 }
 
@@ -1029,7 +1586,7 @@ func GetGiftAccounts(c *gin.Context) {
 	}
 
 	var accounts []models.GiftAccount
-	if err := db.DB.Where("wedding_id = ?", weddingID).Order("bank_name ASC").Find(&accounts).Error; err != nil {
+	if err := db.DB.Where("wedding_id = ?", weddingID).Order("\"order\" ASC, id ASC").Find(&accounts).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gift accounts"})
 		return
 	}
@@ -1057,6 +1614,7 @@ func CreateGiftAccount(c *gin.Context) {
 		AccountNumber: input.AccountNumber,
 		AccountName:   input.AccountName,
 		QRCodeURL:     input.QRCodeURL,
+		Order:         nextOrder(db.DB, "gift_accounts", weddingScope(weddingID)), // Rekening baru di paling akhir
 	}
 
 	if err := db.DB.Create(&account).Error; err != nil {
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GalleryAlbumInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// galleryAlbumScope membatasi item galeri ke satu album milik wedding (nil = tanpa album)
+func galleryAlbumScope(weddingID uint, albumID *uint) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("wedding_id = ?", weddingID)
+		if albumID == nil {
+			return tx.Where("album_id IS NULL")
+		}
+		return tx.Where("album_id = ?", *albumID)
+	}
+}
+
+// checkGalleryAlbum memastikan album (jika diisi) milik wedding ini
+func checkGalleryAlbum(albumID *uint, weddingID uint) error {
+	if albumID == nil {
+		return nil
+	}
+	var count int64
+	db.DB.Model(&models.GalleryAlbum{}).Where("id = ? AND wedding_id = ?", *albumID, weddingID).Count(&count)
+	if count == 0 {
+		return errors.New("album not found")
+	}
+	return nil
+}
+
+// clearOtherCovers memastikan hanya item ini yang menjadi sampul di albumnya
+func clearOtherCovers(tx *gorm.DB, item models.Gallery) error {
+	return tx.Model(&models.Gallery{}).
+		Scopes(galleryAlbumScope(item.WeddingID, item.AlbumID)).
+		Where("id <> ? AND is_cover = ?", item.ID, true).
+		Update("is_cover", false).Error
+}
+
+// orderedAlbumItems mengurutkan item di dalam album
+func orderedAlbumItems(tx *gorm.DB) *gorm.DB {
+	return tx.Order("galleries.\"order\" ASC, galleries.id ASC")
+}
+
+// orderedAlbums mengurutkan album
+func orderedAlbums(tx *gorm.DB) *gorm.DB {
+	return tx.Order("gallery_albums.\"order\" ASC, gallery_albums.id ASC")
+}
+
+// attachAlbumCovers mengisi CoverURL: item bertanda sampul, atau item pertama (Items harus sudah urut)
+func attachAlbumCovers(albums []models.GalleryAlbum) {
+	for i := range albums {
+		albums[i].CoverURL = ""
+		for _, item := range albums[i].Items {
+			if item.IsCover {
+				albums[i].CoverURL = item.FileURL
+				break
+			}
+		}
+		if albums[i].CoverURL == "" && len(albums[i].Items) > 0 {
+			albums[i].CoverURL = albums[i].Items[0].FileURL
+		}
+	}
+}
+
+// --- Gallery Album (Admin) Handlers ---
+
+// GetGalleryAlbums mengambil semua album beserta isinya, sesuai urutan
+func GetGalleryAlbums(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var albums []models.GalleryAlbum
+	if err := db.DB.Preload("Items", orderedAlbumItems).Scopes(orderedAlbums).
+		Where("wedding_id = ?", weddingID).Find(&albums).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch albums"})
+		return
+	}
+
+	attachAlbumCovers(albums)
+	c.JSON(http.StatusOK, albums)
+}
+
+// CreateGalleryAlbum membuat album baru di urutan paling akhir
+func CreateGalleryAlbum(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GalleryAlbumInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Album name is required"})
+		return
+	}
+
+	album := models.GalleryAlbum{
+		WeddingID:   weddingID,
+		Name:        input.Name,
+		Description: strings.TrimSpace(input.Description),
+		Order:       nextOrder(db.DB, "gallery_albums", weddingScope(weddingID)),
+	}
+	if err := db.DB.Create(&album).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create album"})
+		return
+	}
+	album.Items = []models.Gallery{}
+	c.JSON(http.StatusCreated, album)
+}
+
+// UpdateGalleryAlbum mengubah nama/deskripsi album
+func UpdateGalleryAlbum(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GalleryAlbumInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Album name is required"})
+		return
+	}
+
+	var album models.GalleryAlbum
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&album).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Album not found"})
+		return
+	}
+
+	album.Name = input.Name
+	album.Description = strings.TrimSpace(input.Description)
+	if err := db.DB.Save(&album).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update album"})
+		return
+	}
+	c.JSON(http.StatusOK, album)
+}
+
+// DeleteGalleryAlbum menghapus album; item di dalamnya tidak dihapus, hanya dikeluarkan dari album
+func DeleteGalleryAlbum(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var album models.GalleryAlbum
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&album).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Album not found"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Item dipindah ke akhir daftar "tanpa album"; tanda sampul dilepas agar tidak bentrok
+		start := nextOrder(tx, "galleries", galleryAlbumScope(weddingID, nil))
+		if err := tx.Model(&models.Gallery{}).Where("album_id = ?", album.ID).
+			Updates(map[string]interface{}{
+				"album_id": nil,
+				"is_cover": false,
+				"order":    gorm.Expr(`"order" + ?`, start),
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&album).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete album"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Album deleted"})
+}
+
+// SetGalleryCover menjadikan item galeri sebagai sampul albumnya
+func SetGalleryCover(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var item models.Gallery
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gallery item not found"})
+		return
+	}
+
+	item.IsCover = true
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := clearOtherCovers(tx, item); err != nil {
+			return err
+		}
+		return tx.Model(&item).Update("is_cover", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cover"})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
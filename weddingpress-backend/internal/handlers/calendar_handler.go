@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// toCalendarEvent mengubah acara menjadi entri kalender di zona waktu acara.
+// Acara tanpa jam mulai ditulis sebagai acara sehari penuh.
+func toCalendarEvent(e models.Event, weddingTitle string) services.CalendarEvent {
+	summary := e.Name
+	if weddingTitle != "" {
+		summary = e.Name + " - " + weddingTitle
+	}
+
+	location := e.Address
+	if e.VenueName != "" {
+		location = strings.TrimSpace(e.VenueName + ", " + e.Address)
+		location = strings.TrimSuffix(location, ",")
+	}
+
+	description := e.Address
+	if e.ParkingNotes != "" {
+		description = strings.TrimSpace(description + "\n\nParkir: " + e.ParkingNotes)
+	}
+	if e.MapsURL != "" {
+		description = strings.TrimSpace(description + "\n\nPeta: " + e.MapsURL)
+	}
+
+	loc := eventLocation(e)
+	ce := services.CalendarEvent{
+		UID:         fmt.Sprintf("event-%d@weddingpress", e.ID),
+		Summary:     summary,
+		Description: description,
+		Location:    location,
+		URL:         e.MapsURL,
+		Latitude:    e.Latitude,
+		Longitude:   e.Longitude,
+		Start:       e.StartAt.In(loc),
+	}
+	if e.StartTime == "" {
+		ce.AllDay = true
+		return ce
+	}
+	if e.EndAt != nil {
+		ce.End = e.EndAt.In(loc)
+	}
+	return ce
+}
+
+// writeICalendar mengirim acara sebagai file .ics
+func writeICalendar(c *gin.Context, filename, calendarName, weddingTitle string, events []models.Event) {
+	calendarEvents := make([]services.CalendarEvent, 0, len(events))
+	for _, e := range events {
+		calendarEvents = append(calendarEvents, toCalendarEvent(e, weddingTitle))
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(services.BuildICalendar(calendarName, calendarEvents)))
+}
+
+// publicBaseURL mengembalikan URL dasar API untuk tautan publik
+func publicBaseURL(c *gin.Context) string {
+	if base := os.Getenv("PUBLIC_API_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// GetGuestCalendar (publik) mengembalikan file .ics berisi acara yang boleh dilihat tamu
+func GetGuestCalendar(c *gin.Context) {
+	var guest models.Guest
+	if err := db.DB.Where("slug = ?", c.Param("guest_slug")).First(&guest).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Undangan tidak ditemukan"})
+		return
+	}
+
+	var wedding models.Wedding
+	if err := db.DB.Preload("Events", visibleEventsScope(guest)).First(&wedding, guest.WeddingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data pernikahan tidak ditemukan"})
+		return
+	}
+
+	writeICalendar(c, "undangan.ics", wedding.WeddingTitle, wedding.WeddingTitle, wedding.Events)
+}
+
+// GetWeddingCalendarFeed (publik, pakai token) mengembalikan feed .ics berisi semua acara wedding
+func GetWeddingCalendarFeed(c *gin.Context) {
+	token := c.Param("token")
+	if len(token) < 16 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	var wedding models.Wedding
+	err := db.DB.
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("events.start_at ASC") }).
+		Where("calendar_token = ?", token).
+		First(&wedding).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	writeICalendar(c, "wedding.ics", wedding.WeddingTitle, wedding.WeddingTitle, wedding.Events)
+}
+
+func calendarFeedResponse(c *gin.Context, token string) gin.H {
+	url := publicBaseURL(c) + "/api/v1/calendar/" + token + "/feed.ics"
+	return gin.H{
+		"url":        url,
+		"webcal_url": "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://"),
+	}
+}
+
+// GetCalendarFeedURL (admin) mengembalikan URL feed kalender, membuat token jika belum ada
+func GetCalendarFeedURL(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var wedding models.Wedding
+	if err := db.DB.First(&wedding, weddingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	if wedding.CalendarToken == "" {
+		wedding.CalendarToken, err = services.RandomToken(20)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+			return
+		}
+		if err := db.DB.Model(&wedding).Update("calendar_token", wedding.CalendarToken).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, calendarFeedResponse(c, wedding.CalendarToken))
+}
+
+// RegenerateCalendarFeed (admin) membuat token baru; URL feed lama tidak berlaku lagi
+func RegenerateCalendarFeed(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	token, err := services.RandomToken(20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate calendar feed"})
+		return
+	}
+	if err := db.DB.Model(&models.Wedding{ID: weddingID}).Update("calendar_token", token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarFeedResponse(c, token))
+}
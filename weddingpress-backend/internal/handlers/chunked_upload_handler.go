@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// Sesi upload yang tidak selesai dalam waktu ini dihapus beserta potongannya
+	uploadSessionTTL = 24 * time.Hour
+	// Selang pembersihan sesi kedaluwarsa (lihat StartUploadSessionCleanup)
+	uploadSessionCleanupInterval = time.Hour
+	// Maks. sesi upload bertahap yang berjalan bersamaan per wedding
+	maxUploadSessionsPerWedding = 5
+)
+
+// errTooManyUploadSessions menandai wedding yang sudah punya terlalu banyak sesi aktif
+var errTooManyUploadSessions = errors.New("too many upload sessions in progress")
+
+type InitiateUploadInput struct {
+	Filename  string `json:"filename"`
+	Size      int64  `json:"size" binding:"required"`
+	Purpose   string `json:"purpose"`
+	ChunkSize int64  `json:"chunk_size"` // Opsional, default 8 MB
+	SHA256    string `json:"sha256"`     // Opsional di sini, wajib paling lambat saat complete
+}
+
+type CompleteUploadInput struct {
+	SHA256 string `json:"sha256"`
+}
+
+// findUploadSession mengambil sesi upload milik wedding yang belum kedaluwarsa
+func findUploadSession(c *gin.Context, weddingID uint) (models.UploadSession, bool) {
+	var session models.UploadSession
+	if err := db.DB.Where("id = ? AND wedding_id = ? AND expires_at > ?", c.Param("id"), weddingID, time.Now()).
+		First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload session not found or expired"})
+		return session, false
+	}
+	return session, true
+}
+
+// chunkLength mengembalikan ukuran potongan ke-index (potongan terakhir bisa lebih kecil)
+func chunkLength(session models.UploadSession, index int) int64 {
+	if index == session.TotalChunks-1 {
+		return session.TotalSize - int64(index)*session.ChunkSize
+	}
+	return session.ChunkSize
+}
+
+// pendingUploadBytes menjumlahkan ukuran sesi upload wedding yang masih berjalan
+// (selain sesi exceptID); ruang ini sudah "dipesan" walau asset-nya belum tercatat
+func pendingUploadBytes(tx *gorm.DB, weddingID uint, exceptID string) (int64, error) {
+	var pending int64
+	err := tx.Model(&models.UploadSession{}).
+		Where("wedding_id = ? AND id <> ? AND expires_at > ?", weddingID, exceptID, time.Now()).
+		Select("COALESCE(SUM(total_size), 0)").Scan(&pending).Error
+	return pending, err
+}
+
+// StartUploadSessionCleanup menjalankan pembersihan sesi upload kedaluwarsa secara berkala
+// di background (dipanggil sekali saat server mulai)
+func StartUploadSessionCleanup() {
+	go func() {
+		ticker := time.NewTicker(uploadSessionCleanupInterval)
+		defer ticker.Stop()
+		for {
+			cleanupExpiredUploadSessions()
+			<-ticker.C
+		}
+	}()
+}
+
+// cleanupExpiredUploadSessions menghapus sesi kedaluwarsa beserta file sementaranya
+func cleanupExpiredUploadSessions() {
+	var expired []models.UploadSession
+	if err := db.DB.Where("expires_at <= ?", time.Now()).Find(&expired).Error; err != nil {
+		log.Printf("Failed to find expired upload sessions: %v", err)
+		return
+	}
+	for _, session := range expired {
+		if err := services.RemoveChunks(session.ID); err != nil {
+			log.Printf("Failed to remove chunks of upload session %s: %v", session.ID, err)
+			continue
+		}
+		db.DB.Delete(&session)
+	}
+}
+
+// InitiateChunkedUpload memulai sesi upload bertahap. Ukuran & kuota dicek di awal
+// agar file yang pasti ditolak tidak perlu dikirim.
+func InitiateChunkedUpload(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input InitiateUploadInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input.Purpose = strings.ToLower(strings.TrimSpace(input.Purpose))
+	if input.Purpose == "" {
+		input.Purpose = services.PurposeGallery
+	}
+	if err := services.CheckChunkedUploadSize(input.Size, input.Purpose); err != nil {
+		writeUploadError(c, err)
+		return
+	}
+	if input.ChunkSize == 0 {
+		input.ChunkSize = services.DefaultChunkSize
+	}
+	if input.ChunkSize < services.MinChunkSize || input.ChunkSize > services.MaxChunkSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("chunk_size must be between %d and %d bytes",
+			services.MinChunkSize, services.MaxChunkSize)})
+		return
+	}
+	checksum, err := services.NormalizeSHA256(input.SHA256)
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+
+	sessionID, err := services.RandomToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start upload"})
+		return
+	}
+	session := models.UploadSession{
+		ID:          sessionID,
+		WeddingID:   weddingID,
+		Filename:    strings.TrimSpace(input.Filename),
+		Purpose:     input.Purpose,
+		TotalSize:   input.Size,
+		ChunkSize:   input.ChunkSize,
+		TotalChunks: services.ChunkCount(input.Size, input.ChunkSize),
+		SHA256:      checksum,
+		Status:      models.UploadStatusUploading,
+		ExpiresAt:   time.Now().Add(uploadSessionTTL),
+	}
+	// Kuota dihitung bersama sesi lain yang masih berjalan, di bawah kunci baris wedding
+	// agar sesi paralel tidak sama-sama memesan sisa kuota yang sama
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		usage, err := lockStorageUsage(tx, weddingID)
+		if err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&models.UploadSession{}).Where("wedding_id = ? AND expires_at > ?", weddingID, time.Now()).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active >= maxUploadSessionsPerWedding {
+			return errTooManyUploadSessions
+		}
+		pending, err := pendingUploadBytes(tx, weddingID, "")
+		if err != nil {
+			return err
+		}
+		usage.UsedBytes += pending
+		if err := usage.CheckQuota(input.Size); err != nil {
+			return err
+		}
+		return tx.Create(&session).Error
+	})
+	if errors.Is(err, errTooManyUploadSessions) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf(
+			"Too many uploads in progress (max %d); complete or abort one first", maxUploadSessionsPerWedding)})
+		return
+	}
+	var uploadErr *services.UploadError
+	if errors.As(err, &uploadErr) {
+		writeUploadError(c, err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start upload"})
+		return
+	}
+	session.ReceivedChunks = []int{}
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetChunkedUpload menampilkan status sesi, termasuk potongan yang sudah diterima
+// (untuk melanjutkan upload yang terputus)
+func GetChunkedUpload(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	session, ok := findUploadSession(c, weddingID)
+	if !ok {
+		return
+	}
+	session.ReceivedChunks = services.ReceivedChunks(session.ID, session.TotalChunks)
+	c.JSON(http.StatusOK, session)
+}
+
+// UploadChunk menerima satu potongan sebagai body mentah (application/octet-stream).
+// Header opsional X-Part-SHA256 berisi checksum potongan.
+func UploadChunk(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	session, ok := findUploadSession(c, weddingID)
+	if !ok {
+		return
+	}
+	if session.Status != models.UploadStatusUploading {
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload is already being completed"})
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 || index >= session.TotalChunks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Part index must be between 0 and %d", session.TotalChunks-1)})
+		return
+	}
+	partChecksum, err := services.NormalizeSHA256(c.GetHeader("X-Part-SHA256"))
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+
+	expected := chunkLength(session, index)
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, expected+1)
+	if err := services.WriteChunk(session.ID, index, body, expected, partChecksum); err != nil {
+		writeUploadError(c, err)
+		return
+	}
+
+	received := services.ReceivedChunks(session.ID, session.TotalChunks)
+	c.JSON(http.StatusOK, gin.H{
+		"part":         index,
+		"received":     len(received),
+		"total_chunks": session.TotalChunks,
+	})
+}
+
+// CompleteChunkedUpload menggabungkan potongan, memverifikasi checksum SHA-256,
+// lalu mengunggah file ke storage seperti HandleUpload
+func CompleteChunkedUpload(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input CompleteUploadInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, ok := findUploadSession(c, weddingID)
+	if !ok {
+		return
+	}
+	checksum, err := services.NormalizeSHA256(input.SHA256)
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+	if checksum == "" {
+		checksum = session.SHA256
+	}
+	if checksum == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sha256 of the whole file is required"})
+		return
+	}
+	if session.SHA256 != "" && checksum != session.SHA256 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sha256 does not match the checksum given when the upload started"})
+		return
+	}
+
+	// Kunci sesi agar complete tidak berjalan dua kali bersamaan
+	result := db.DB.Model(&models.UploadSession{}).
+		Where("id = ? AND status = ?", session.ID, models.UploadStatusUploading).
+		Update("status", models.UploadStatusAssembling)
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload is already being completed"})
+		return
+	}
+	// Jika gagal, file gabungan dibuang dan sesi dibuka lagi agar potongan bisa
+	// diperbaiki lalu dicoba ulang
+	completed := false
+	defer func() {
+		if !completed {
+			if err := services.RemoveAssembled(session.ID); err != nil {
+				log.Printf("Failed to remove assembled file of upload session %s: %v", session.ID, err)
+			}
+			db.DB.Model(&session).Update("status", models.UploadStatusUploading)
+		}
+	}()
+
+	usage, err := weddingStorageUsage(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check storage quota"})
+		return
+	}
+	pending, err := pendingUploadBytes(db.DB, weddingID, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check storage quota"})
+		return
+	}
+	usage.UsedBytes += pending
+	if err := usage.CheckQuota(session.TotalSize); err != nil {
+		writeUploadError(c, err)
+		return
+	}
+
+	path, sum, err := services.AssembleChunks(session.ID, session.TotalChunks, session.TotalSize)
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+	if sum != checksum {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    "Checksum mismatch: the assembled file is corrupted, re-upload the parts",
+			"expected": checksum,
+			"actual":   sum,
+		})
+		return
+	}
+
+	object, err := services.UploadChunked(path, session.TotalSize, weddingMediaFolder(weddingID), session.Purpose)
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+	if err := recordMediaAssets(db.DB, weddingID, object); err != nil {
+		discardUploads(object) // Termasuk jika kuota sudah terpakai upload lain yang berjalan bersamaan
+		writeUploadError(c, err)
+		return
+	}
+
+	completed = true
+	db.DB.Delete(&session)
+	if err := services.RemoveChunks(session.ID); err != nil {
+		log.Printf("Failed to remove chunks of upload session %s: %v", session.ID, err)
+	}
+
+	response := uploadResponse(object)
+	response["sha256"] = sum
+	c.JSON(http.StatusOK, response)
+}
+
+// AbortChunkedUpload membatalkan sesi dan menghapus potongan yang sudah diterima
+func AbortChunkedUpload(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	session, ok := findUploadSession(c, weddingID)
+	if !ok {
+		return
+	}
+	if session.Status != models.UploadStatusUploading {
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload is already being completed"})
+		return
+	}
+
+	if err := services.RemoveChunks(session.ID); err != nil {
+		log.Printf("Failed to remove chunks of upload session %s: %v", session.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to abort upload"})
+		return
+	}
+	if err := db.DB.Delete(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to abort upload"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Upload aborted"})
+}
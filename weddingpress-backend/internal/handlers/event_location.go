@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+)
+
+// normalizeLocation memvalidasi link peta & koordinat.
+// Jika koordinat kosong, dicoba diambil dari maps_url.
+func (in *EventInput) normalizeLocation() error {
+	in.MapsURL = strings.TrimSpace(in.MapsURL)
+	in.VenueName = strings.TrimSpace(in.VenueName)
+	in.ParkingNotes = strings.TrimSpace(in.ParkingNotes)
+
+	if in.MapsURL != "" {
+		u, err := url.Parse(in.MapsURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("maps_url must be a valid http(s) link")
+		}
+	}
+
+	if (in.Latitude == nil) != (in.Longitude == nil) {
+		return errors.New("latitude and longitude must be set together")
+	}
+	if in.Latitude != nil {
+		if !services.ValidCoordinates(*in.Latitude, *in.Longitude) {
+			return errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+		}
+		return nil
+	}
+
+	if lat, lng, ok := services.ParseMapsCoordinates(in.MapsURL); ok {
+		in.Latitude = &lat
+		in.Longitude = &lng
+	}
+	return nil
+}
+
+// eventDirections membuat link petunjuk arah ke lokasi acara.
+// Tanpa koordinat, hanya Google Maps (berdasarkan alamat) yang bisa dibuat.
+func eventDirections(e models.Event) *models.EventDirections {
+	if e.Latitude != nil && e.Longitude != nil {
+		label := e.VenueName
+		if label == "" {
+			label = e.Name
+		}
+		return &models.EventDirections{
+			GoogleMaps: services.GoogleMapsDirectionsURL(*e.Latitude, *e.Longitude),
+			Waze:       services.WazeDirectionsURL(*e.Latitude, *e.Longitude),
+			AppleMaps:  services.AppleMapsDirectionsURL(*e.Latitude, *e.Longitude, label),
+		}
+	}
+
+	destination := strings.TrimSpace(strings.Join([]string{e.VenueName, e.Address}, " "))
+	if destination == "" {
+		return nil
+	}
+	return &models.EventDirections{GoogleMaps: services.GoogleMapsDirectionsToAddress(destination)}
+}
+
+// attachDirections mengisi link petunjuk arah pada setiap acara
+func attachDirections(events []models.Event) {
+	for i := range events {
+		events[i].Directions = eventDirections(events[i])
+	}
+}
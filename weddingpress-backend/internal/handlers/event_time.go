@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+)
+
+// Format waktu lokal (tanpa offset) yang diterima untuk start_at/end_at
+var localTimestampLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// weddingLocation memuat zona waktu default milik wedding
+func weddingLocation(weddingID uint) (*time.Location, error) {
+	var wedding models.Wedding
+	if err := db.DB.Select("id", "timezone").First(&wedding, weddingID).Error; err != nil {
+		return nil, err
+	}
+	return services.LoadTimezone(wedding.Timezone)
+}
+
+// parseEventTimestamp membaca waktu RFC3339 (dengan offset) atau waktu lokal di zona loc
+func parseEventTimestamp(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q (use RFC3339 or YYYY-MM-DDTHH:MM)", value)
+}
+
+// eventTimes adalah waktu acara hasil resolveTimes
+type eventTimes struct {
+	Start  time.Time
+	End    *time.Time
+	Loc    *time.Location
+	AllDay bool // Format lama tanpa start_time: acara sehari penuh
+}
+
+// resolveTimes menghitung waktu mulai/selesai acara dari input.
+// start_at/end_at diutamakan; jika kosong, dipakai format lama date + start_time/end_time.
+// Tanpa timezone di input, dipakai fallbackTimezone (zona acara yang sudah tersimpan),
+// lalu zona waktu wedding.
+func (in *EventInput) resolveTimes(weddingID uint, fallbackTimezone string) (eventTimes, error) {
+	var times eventTimes
+	var err error
+	switch {
+	case strings.TrimSpace(in.Timezone) != "":
+		times.Loc, err = time.LoadLocation(strings.TrimSpace(in.Timezone))
+		if err != nil {
+			return times, fmt.Errorf("invalid timezone %q", in.Timezone)
+		}
+	case fallbackTimezone != "":
+		if times.Loc, err = services.LoadTimezone(fallbackTimezone); err != nil {
+			return times, err
+		}
+	default:
+		if times.Loc, err = weddingLocation(weddingID); err != nil {
+			return times, err
+		}
+	}
+	loc := times.Loc
+
+	switch {
+	case strings.TrimSpace(in.StartAt) != "":
+		if times.Start, err = parseEventTimestamp(in.StartAt, loc); err != nil {
+			return times, err
+		}
+		if strings.TrimSpace(in.EndAt) != "" {
+			t, err := parseEventTimestamp(in.EndAt, loc)
+			if err != nil {
+				return times, err
+			}
+			times.End = &t
+		}
+	case strings.TrimSpace(in.Date) != "":
+		date, err := parseDateString(in.Date, loc)
+		if err != nil {
+			return times, err
+		}
+		if in.StartTime != "" {
+			if _, _, ok := services.ParseClock(in.StartTime); !ok {
+				return times, fmt.Errorf("invalid start_time %q (use HH:MM)", in.StartTime)
+			}
+		}
+		times.Start = services.CombineDateAndClock(date, in.StartTime, loc)
+		times.AllDay = strings.TrimSpace(in.StartTime) == ""
+		if in.EndTime != "" && !times.AllDay {
+			if _, _, ok := services.ParseClock(in.EndTime); !ok {
+				return times, fmt.Errorf("invalid end_time %q (use HH:MM)", in.EndTime)
+			}
+			t := services.CombineDateAndClock(date, in.EndTime, loc)
+			times.End = &t
+		}
+	default:
+		return times, errors.New("start_at (or date) is required")
+	}
+
+	if times.End != nil && !times.End.After(times.Start) {
+		return times, errors.New("event end must be after its start")
+	}
+	return times, nil
+}
+
+// setEventTimes menyimpan waktu acara beserta kolom lama (date, start_time, end_time)
+// yang diturunkan di zona waktu acara. Acara sehari penuh disimpan tanpa start_time.
+func setEventTimes(event *models.Event, times eventTimes) {
+	localStart := times.Start.In(times.Loc)
+	event.StartAt = times.Start.UTC()
+	event.Timezone = times.Loc.String()
+	event.Date = services.DateOnly(localStart)
+	event.StartTime = localStart.Format("15:04")
+	if times.AllDay {
+		event.StartTime = ""
+	}
+	event.EndAt = nil
+	event.EndTime = ""
+	if times.End != nil {
+		utcEnd := times.End.UTC()
+		event.EndAt = &utcEnd
+		event.EndTime = times.End.In(times.Loc).Format("15:04")
+	}
+}
+
+// eventLocation memuat zona waktu acara (jatuh ke default jika kosong/tidak dikenal)
+func eventLocation(e models.Event) *time.Location {
+	if loc, err := services.LoadTimezone(e.Timezone); err == nil {
+		return loc
+	}
+	loc, err := services.LoadTimezone("")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
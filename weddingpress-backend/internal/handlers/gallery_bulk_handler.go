@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxBulkGalleryFiles         = 100
+	defaultGalleryUploadWorkers = 4
+	defaultGalleryBulkMaxMB     = 1024
+)
+
+// BulkUploadResult adalah hasil upload satu file pada upload massal
+type BulkUploadResult struct {
+	Index    int             `json:"index"` // Posisi file di form (mulai dari 0)
+	Filename string          `json:"filename"`
+	Success  bool            `json:"success"`
+	Error    string          `json:"error,omitempty"`
+	Item     *models.Gallery `json:"item,omitempty"`
+}
+
+// galleryUploadWorkers membaca batas upload paralel dari GALLERY_UPLOAD_CONCURRENCY
+func galleryUploadWorkers() int {
+	if n, err := strconv.Atoi(os.Getenv("GALLERY_UPLOAD_CONCURRENCY")); err == nil && n > 0 {
+		return n
+	}
+	return defaultGalleryUploadWorkers
+}
+
+// galleryBulkMaxBytes membaca batas total body upload massal dari GALLERY_BULK_MAX_MB
+func galleryBulkMaxBytes() int64 {
+	mb := int64(defaultGalleryBulkMaxMB)
+	if n, err := strconv.ParseInt(os.Getenv("GALLERY_BULK_MAX_MB"), 10, 64); err == nil && n > 0 {
+		mb = n
+	}
+	return mb << 20
+}
+
+// uploadFilesConcurrently memvalidasi & mengunggah file secara paralel (maks. workers sekaligus).
+// File dengan rejected[i] != nil dilewati. errs[i] berisi alasan jika file ke-i gagal.
+func uploadFilesConcurrently(files []*multipart.FileHeader, rejected []error, folder, purpose string, workers int) ([]services.UploadedFile, []error) {
+	objects := make([]services.UploadedFile, len(files))
+	errs := make([]error, len(files))
+	copy(errs, rejected)
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		if errs[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, file *multipart.FileHeader) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			objects[i], errs[i] = services.UploadFile(file, folder, purpose)
+		}(i, file)
+	}
+	wg.Wait()
+	return objects, errs
+}
+
+// BulkUploadGallery mengunggah banyak file sekaligus (field "files") ke galeri.
+// Field opsional: "album_id". Baris galeri dibuat dalam satu transaksi untuk semua file
+// yang berhasil diunggah; respons berisi hasil per file.
+func BulkUploadGallery(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	// Batasi ukuran total body sebelum form dibaca (semua file disimpan ke disk sementara dulu);
+	// batas per file tetap dicek oleh aturan upload galeri
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, galleryBulkMaxBytes())
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request is too large (max " + strconv.FormatInt(tooLarge.Limit>>20, 10) + " MB per request)"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form"})
+		return
+	}
+	files := form.File["files"]
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No files uploaded. Make sure the field name is 'files'"})
+		return
+	}
+	if len(files) > maxBulkGalleryFiles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many files (max " + strconv.Itoa(maxBulkGalleryFiles) + " per request)"})
+		return
+	}
+
+	var albumID *uint
+	if raw := c.PostForm("album_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid album_id"})
+			return
+		}
+		value := uint(id)
+		albumID = &value
+	}
+	if err := checkGalleryAlbum(albumID, weddingID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	log.Printf("User %d is uploading %d gallery files...", userID, len(files))
+
+	usage, err := weddingStorageUsage(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check storage quota"})
+		return
+	}
+	objects, uploadErrs := uploadFilesConcurrently(files, reserveStorageQuota(usage, files),
+		weddingMediaFolder(weddingID), services.PurposeGallery, galleryUploadWorkers())
+
+	results := make([]BulkUploadResult, len(files))
+	uploaded := make([]int, 0, len(files)) // Indeks file yang berhasil diunggah ke storage
+	for i, file := range files {
+		results[i] = BulkUploadResult{Index: i, Filename: file.Filename}
+		if uploadErrs[i] != nil {
+			var uploadErr *services.UploadError
+			if errors.As(uploadErrs[i], &uploadErr) {
+				results[i].Error = uploadErr.Message
+			} else {
+				log.Printf("Failed to upload %s: %v", file.Filename, uploadErrs[i])
+				results[i].Error = "Failed to upload file"
+			}
+			continue
+		}
+		uploaded = append(uploaded, i)
+	}
+
+	// Kuota dicek ulang per file di bawah kunci (ukuran asset termasuk varian gambar), jadi
+	// hanya file yang tidak lagi muat yang gagal
+	items := make([]models.Gallery, 0, len(uploaded))
+	itemIndex := make([]int, 0, len(uploaded)) // items[k] milik results[itemIndex[k]]
+	var rejected []services.UploadedFile
+	if len(uploaded) > 0 {
+		err := db.DB.Transaction(func(tx *gorm.DB) error {
+			usage, err := lockStorageUsage(tx, weddingID)
+			if err != nil {
+				return err
+			}
+			order := nextOrder(tx, "galleries", galleryAlbumScope(weddingID, albumID))
+			for _, i := range uploaded {
+				asset := mediaAssetFromUpload(weddingID, objects[i])
+				if err := usage.CheckQuota(asset.Size); err != nil {
+					results[i].Error = err.Error()
+					rejected = append(rejected, objects[i])
+					continue
+				}
+				if err := tx.Create(&asset).Error; err != nil {
+					return err
+				}
+				usage.UsedBytes += asset.Size
+
+				item := galleryItemFromUpload(objects[i])
+				item.WeddingID = weddingID
+				item.AlbumID = albumID
+				item.Order = order // Urutan mengikuti urutan file di form
+				items = append(items, item)
+				itemIndex = append(itemIndex, i)
+				order++
+			}
+			if len(items) == 0 {
+				return nil
+			}
+			return tx.Create(&items).Error
+		})
+		if err != nil {
+			log.Printf("Failed to save bulk gallery items: %v", err)
+			rejected = rejected[:0]
+			for _, i := range uploaded {
+				results[i].Error = "Failed to save gallery item"
+				rejected = append(rejected, objects[i])
+			}
+			items = nil
+		}
+	}
+	discardUploads(rejected...)
+	for k := range items {
+		results[itemIndex[k]].Success = true
+		results[itemIndex[k]].Item = &items[k]
+	}
+
+	status := http.StatusCreated
+	if len(items) != len(files) {
+		status = http.StatusMultiStatus // Sebagian (atau semua) file gagal
+	}
+	c.JSON(status, gin.H{
+		"uploaded": len(items),
+		"failed":   len(files) - len(items),
+		"results":  results,
+	})
+}
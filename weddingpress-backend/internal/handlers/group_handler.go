@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GuestGroupInput struct {
+	Name         string `json:"name" binding:"required"`
+	DefaultQuota *int   `json:"default_quota"` // Opsional, default 2
+	Greeting     string `json:"greeting"`
+	EventIDs     []uint `json:"event_ids"` // Acara yang terlihat; kosong = semua acara
+}
+
+type MergeGroupInput struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+// GuestGroupResponse menambahkan jumlah tamu ke data grup
+type GuestGroupResponse struct {
+	models.GuestGroup
+	GuestCount int64 `json:"guest_count"`
+}
+
+var errGroupNotFound = errors.New("group not found")
+
+// findOrCreateGuestGroup mencari grup berdasarkan nama (tidak peka huruf besar/kecil),
+// dan membuatnya jika belum ada. Dipakai oleh input tamu yang masih mengirim nama grup.
+func findOrCreateGuestGroup(tx *gorm.DB, weddingID uint, name string) (*models.GuestGroup, error) {
+	var group models.GuestGroup
+	err := tx.Where("wedding_id = ? AND LOWER(name) = LOWER(?)", weddingID, name).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		group = models.GuestGroup{WeddingID: weddingID, Name: name}
+		err = tx.Create(&group).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// resolveGuestGroup menentukan grup tamu dari group_id (diutamakan) atau nama grup.
+// Mengembalikan nil jika tamu tidak masuk grup mana pun.
+func resolveGuestGroup(tx *gorm.DB, weddingID uint, groupID *uint, name string) (*models.GuestGroup, error) {
+	if groupID != nil {
+		var group models.GuestGroup
+		if err := tx.Where("id = ? AND wedding_id = ?", *groupID, weddingID).First(&group).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errGroupNotFound
+			}
+			return nil, err
+		}
+		return &group, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	return findOrCreateGuestGroup(tx, weddingID, name)
+}
+
+// applyGuestGroup mengisi GroupID & salinan nama grup pada tamu
+func applyGuestGroup(guest *models.Guest, group *models.GuestGroup) {
+	if group == nil {
+		guest.GroupID = nil
+		guest.Group = ""
+		return
+	}
+	guest.GroupID = &group.ID
+	guest.Group = group.Name
+}
+
+// loadWeddingEvents memastikan semua event ID milik wedding ini
+func loadWeddingEvents(weddingID uint, ids []uint) ([]models.Event, error) {
+	events := []models.Event{}
+	if len(ids) == 0 {
+		return events, nil
+	}
+	if err := db.DB.Where("id IN ? AND wedding_id = ?", ids, weddingID).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	if len(events) != len(uniqueIDs(ids)) {
+		return nil, errors.New("some events were not found")
+	}
+	return events, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// --- Guest Group (Admin) Handlers ---
+
+// GetGroups mengambil semua grup tamu beserta jumlah tamu dan acara yang terlihat
+func GetGroups(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var groups []models.GuestGroup
+	if err := db.DB.Preload("VisibleEvents").Where("wedding_id = ?", weddingID).Order("name ASC").Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+
+	type groupCount struct {
+		GroupID uint
+		Total   int64
+	}
+	var counts []groupCount
+	if err := db.DB.Model(&models.Guest{}).
+		Select("group_id, COUNT(*) AS total").
+		Where("wedding_id = ? AND group_id IS NOT NULL", weddingID).
+		Group("group_id").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count guests"})
+		return
+	}
+	countByGroup := make(map[uint]int64, len(counts))
+	for _, gc := range counts {
+		countByGroup[gc.GroupID] = gc.Total
+	}
+
+	response := make([]GuestGroupResponse, 0, len(groups))
+	for _, g := range groups {
+		response = append(response, GuestGroupResponse{GuestGroup: g, GuestCount: countByGroup[g.ID]})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func CreateGroup(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GuestGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := validateAttendanceQuota(input.DefaultQuota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing int64
+	db.DB.Model(&models.GuestGroup{}).Where("wedding_id = ? AND LOWER(name) = LOWER(?)", weddingID, input.Name).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists"})
+		return
+	}
+
+	events, err := loadWeddingEvents(weddingID, input.EventIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group := models.GuestGroup{
+		WeddingID:     weddingID,
+		Name:          input.Name,
+		Greeting:      input.Greeting,
+		VisibleEvents: events,
+	}
+	if input.DefaultQuota != nil {
+		group.DefaultQuota = *input.DefaultQuota
+	}
+
+	if err := db.DB.Create(&group).Error; err != nil {
+		if isUniqueViolation(err) { // Grup bernama sama dibuat bersamaan
+			c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+		return
+	}
+	c.JSON(http.StatusCreated, group)
+}
+
+// UpdateGroup mengganti nama/pengaturan grup. Nama baru ikut disalin ke semua tamu di grup.
+func UpdateGroup(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GuestGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := validateAttendanceQuota(input.DefaultQuota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var group models.GuestGroup
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&group).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var existing int64
+	db.DB.Model(&models.GuestGroup{}).Where("wedding_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", weddingID, input.Name, group.ID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists, merge the groups instead"})
+		return
+	}
+
+	events, err := loadWeddingEvents(weddingID, input.EventIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group.Name = input.Name
+	group.Greeting = input.Greeting
+	if input.DefaultQuota != nil {
+		group.DefaultQuota = *input.DefaultQuota
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("VisibleEvents").Save(&group).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&group).Association("VisibleEvents").Replace(events); err != nil {
+			return err
+		}
+		// Cascade: salin nama baru ke semua tamu di grup ini
+		return tx.Model(&models.Guest{}).Where("group_id = ?", group.ID).Update("group", group.Name).Error
+	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A group with this name already exists, merge the groups instead"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
+		return
+	}
+
+	group.VisibleEvents = events
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup menghapus grup; tamu di dalamnya menjadi tanpa grup
+func DeleteGroup(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var group models.GuestGroup
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&group).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Guest{}).Where("group_id = ?", group.ID).
+			Updates(map[string]interface{}{"group_id": nil, "group": ""}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&group).Association("VisibleEvents").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM event_audience_groups WHERE guest_group_id = ?", group.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted"})
+}
+
+// MergeGroup memindahkan semua tamu ke grup tujuan lalu menghapus grup asal
+func MergeGroup(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input MergeGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var source, target models.GuestGroup
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&source).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	if err := db.DB.Where("id = ? AND wedding_id = ?", input.TargetID, weddingID).First(&target).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target group not found"})
+		return
+	}
+	if source.ID == target.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge a group into itself"})
+		return
+	}
+
+	var moved int64
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Guest{}).Where("group_id = ?", source.ID).
+			Updates(map[string]interface{}{"group_id": target.ID, "group": target.Name})
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+		if err := tx.Model(&source).Association("VisibleEvents").Clear(); err != nil {
+			return err
+		}
+		// Undangan acara untuk grup asal dialihkan ke grup tujuan
+		err := tx.Exec(`INSERT INTO event_audience_groups (event_id, guest_group_id)
+			SELECT event_id, ? FROM event_audience_groups WHERE guest_group_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM event_audience_groups WHERE guest_group_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Groups merged",
+		"guests_moved": moved,
+		"target":       target,
+	})
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultGuestPhotoLimit = 20
+
+var errGuestPhotoModerated = errors.New("guest photo has already been approved")
+
+type GuestPhotoSettingsInput struct {
+	Enabled bool `json:"enabled"`
+}
+
+type ApproveGuestPhotoInput struct {
+	AlbumID *uint  `json:"album_id"` // Opsional: album tujuan di galeri
+	Caption string `json:"caption"`  // Opsional: menimpa caption dari tamu
+}
+
+// guestPhotoLimit membaca batas foto per tamu dari GUEST_PHOTO_LIMIT (default 20)
+func guestPhotoLimit() int64 {
+	if n, err := strconv.ParseInt(os.Getenv("GUEST_PHOTO_LIMIT"), 10, 64); err == nil && n > 0 {
+		return n
+	}
+	return defaultGuestPhotoLimit
+}
+
+// errGuestPhotoLimitReached menandai tamu yang sudah mencapai batas foto
+var errGuestPhotoLimitReached = errors.New("guest photo limit reached")
+
+// countGuestPhotos menghitung foto yang pernah dikirim tamu
+func countGuestPhotos(tx *gorm.DB, guestID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&models.GuestPhoto{}).Where("guest_id = ?", guestID).Count(&count).Error
+	return count, err
+}
+
+// --- Guest Photo (Public) Handlers ---
+
+// ResolveGuestSlug memuat tamu dari slug undangan (404 jika tidak ada) dan menyimpannya
+// di context sebagai "guest", agar middleware sesudahnya (rate limit) hanya melihat tamu valid
+func ResolveGuestSlug(c *gin.Context) {
+	var guest models.Guest
+	if err := db.DB.Where("slug = ?", c.Param("guest_slug")).First(&guest).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Undangan tidak ditemukan"})
+		return
+	}
+	c.Set("guest", guest)
+	c.Next()
+}
+
+// UploadGuestPhoto menerima foto dari tamu (field "file", opsional "caption").
+// Tamu dikenali dari slug undangan (lihat ResolveGuestSlug); foto masuk antrean moderasi admin.
+func UploadGuestPhoto(c *gin.Context) {
+	guest := c.MustGet("guest").(models.Guest) // Diisi ResolveGuestSlug
+
+	var wedding models.Wedding
+	if err := db.DB.Select("id", "guest_uploads_enabled").First(&wedding, guest.WeddingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data pernikahan tidak ditemukan"})
+		return
+	}
+	if !wedding.GuestUploadsEnabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Upload foto belum dibuka untuk undangan ini"})
+		return
+	}
+
+	// Cek awal agar foto tidak perlu diunggah jika batas sudah tercapai (dicek ulang saat menyimpan)
+	limit := guestPhotoLimit()
+	limitMessage := "Batas foto per tamu (" + strconv.FormatInt(limit, 10) + " foto) sudah tercapai"
+	count, err := countGuestPhotos(db.DB, guest.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengunggah foto"})
+		return
+	}
+	if count >= limit {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": limitMessage})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.UploadRules[services.PurposeGuestPhoto].MaxBytes[services.CategoryImage]+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran foto terlalu besar"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Foto tidak ditemukan. Gunakan field 'file'"})
+		return
+	}
+
+	usage, err := weddingStorageUsage(wedding.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengunggah foto"})
+		return
+	}
+	if usage.CheckQuota(file.Size) != nil {
+		c.JSON(http.StatusInsufficientStorage, gin.H{"error": "Penyimpanan undangan ini sudah penuh"})
+		return
+	}
+
+	object, err := services.UploadFile(file, weddingMediaFolder(wedding.ID), services.PurposeGuestPhoto)
+	if err != nil {
+		var uploadErr *services.UploadError
+		if errors.As(err, &uploadErr) {
+			c.JSON(uploadErr.Status, gin.H{"error": "Foto tidak valid: " + uploadErr.Message})
+			return
+		}
+		log.Printf("Guest photo upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengunggah foto"})
+		return
+	}
+
+	caption := strings.TrimSpace(c.PostForm("caption"))
+	if len(caption) > 255 {
+		caption = caption[:255]
+	}
+	photo := models.GuestPhoto{
+		WeddingID: wedding.ID,
+		GuestID:   guest.ID,
+		Caption:   strings.ToValidUTF8(caption, ""),
+		Status:    models.GuestPhotoPending,
+	}
+	item := galleryItemFromUpload(object)
+	photo.FileURL, photo.ThumbURL, photo.MediumURL = item.FileURL, item.ThumbURL, item.MediumURL
+	photo.Width, photo.Height = item.Width, item.Height
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Baris tamu dikunci agar upload paralel tidak sama-sama lolos hitungan batas foto
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Guest{}, guest.ID).Error; err != nil {
+			return err
+		}
+		count, err := countGuestPhotos(tx, guest.ID)
+		if err != nil {
+			return err
+		}
+		if count >= limit {
+			return errGuestPhotoLimitReached
+		}
+		if err := recordMediaAssets(tx, wedding.ID, object); err != nil {
+			return err
+		}
+		return tx.Create(&photo).Error
+	})
+	if err != nil {
+		discardUploads(object)
+		if errors.Is(err, errGuestPhotoLimitReached) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": limitMessage})
+			return
+		}
+		var uploadErr *services.UploadError
+		if errors.As(err, &uploadErr) { // Kuota habis dipakai upload lain yang berjalan bersamaan
+			c.JSON(http.StatusInsufficientStorage, gin.H{"error": "Penyimpanan undangan ini sudah penuh"})
+			return
+		}
+		log.Printf("Failed to save guest photo: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan foto"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Foto berhasil dikirim, menunggu persetujuan", "photo": photo})
+}
+
+// GetMyGuestPhotos mengambil foto yang pernah dikirim tamu ini beserta statusnya
+func GetMyGuestPhotos(c *gin.Context) {
+	var guest models.Guest
+	if err := db.DB.Where("slug = ?", c.Param("guest_slug")).First(&guest).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Undangan tidak ditemukan"})
+		return
+	}
+
+	var photos []models.GuestPhoto
+	if err := db.DB.Where("guest_id = ?", guest.ID).Order("created_at DESC").Find(&photos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil foto"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"photos":    photos,
+		"limit":     guestPhotoLimit(),
+		"remaining": max(0, guestPhotoLimit()-int64(len(photos))),
+	})
+}
+
+// --- Guest Photo (Admin) Handlers ---
+
+// GetGuestPhotos mengambil antrean foto tamu (filter ?status=pending|approved)
+func GetGuestPhotos(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	query := db.DB.Preload("Guest", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "name")
+	}).Where("wedding_id = ?", weddingID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var photos []models.GuestPhoto
+	if err := query.Order("created_at ASC").Find(&photos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guest photos"})
+		return
+	}
+	c.JSON(http.StatusOK, photos)
+}
+
+// UpdateGuestPhotoSettings membuka/menutup upload foto dari tamu
+func UpdateGuestPhotoSettings(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GuestPhotoSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.DB.Model(&models.Wedding{ID: weddingID}).Update("guest_uploads_enabled", input.Enabled).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update guest photo settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"guest_uploads_enabled": input.Enabled, "per_guest_limit": guestPhotoLimit()})
+}
+
+// ApproveGuestPhoto memindahkan foto tamu ke galeri (di urutan paling akhir album)
+func ApproveGuestPhoto(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input ApproveGuestPhotoInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := checkGalleryAlbum(input.AlbumID, weddingID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var photo models.GuestPhoto
+	if err := db.DB.Preload("Guest").Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&photo).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guest photo not found"})
+		return
+	}
+	if photo.Status != models.GuestPhotoPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Guest photo has already been approved"})
+		return
+	}
+
+	caption := strings.TrimSpace(input.Caption)
+	if caption == "" {
+		caption = photo.Caption
+	}
+	if caption == "" && photo.Guest != nil {
+		caption = "Foto dari " + photo.Guest.Name
+	}
+	item := models.Gallery{
+		WeddingID: weddingID,
+		FileURL:   photo.FileURL,
+		FileType:  mediaTypeImage,
+		Caption:   caption,
+		ThumbURL:  photo.ThumbURL,
+		MediumURL: photo.MediumURL,
+		Width:     photo.Width,
+		Height:    photo.Height,
+		AlbumID:   input.AlbumID,
+	}
+
+	now := time.Now()
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		item.Order = nextOrder(tx, "galleries", galleryAlbumScope(weddingID, input.AlbumID))
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		// Kondisi status mencegah foto yang sama di-approve dua kali bersamaan
+		result := tx.Model(&models.GuestPhoto{}).
+			Where("id = ? AND status = ?", photo.ID, models.GuestPhotoPending).
+			Updates(map[string]interface{}{
+				"status":       models.GuestPhotoApproved,
+				"gallery_id":   item.ID,
+				"moderated_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errGuestPhotoModerated // Sudah diproses request lain
+		}
+		return nil
+	})
+	if errors.Is(err, errGuestPhotoModerated) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Guest photo has already been approved"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve guest photo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Guest photo approved", "gallery_item": item})
+}
+
+// RejectGuestPhoto menolak foto tamu: foto dihapus dari antrean dan dari storage.
+// Foto yang sudah di-approve hanya dihapus dari antrean (item galerinya tetap ada).
+func RejectGuestPhoto(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var photo models.GuestPhoto
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&photo).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guest photo not found"})
+		return
+	}
+
+	if err := db.DB.Delete(&photo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete guest photo"})
+		return
+	}
+	releaseMediaAssets(weddingID, photo.FileURL)
+	c.JSON(http.StatusOK, gin.H{"message": "Guest photo deleted"})
+}
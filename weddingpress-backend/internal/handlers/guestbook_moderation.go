@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Aksi pada log moderasi buku tamu
+const (
+	moderationSubmitted     = "submitted"
+	moderationStatusChanged = "status_changed"
+	moderationDeleted       = "deleted"
+)
+
+var guestBookStatuses = map[string]bool{
+	models.GuestBookPending:  true,
+	models.GuestBookApproved: true,
+	models.GuestBookRejected: true,
+	models.GuestBookHidden:   true,
+}
+
+type GuestBookSettingsInput struct {
+	AutoApprove bool     `json:"auto_approve"`
+	Blocklist   []string `json:"blocklist"` // Kata terlarang tambahan
+
+	MessageLimit *int `json:"message_limit"` // Maks. ucapan per tamu, 0 = tanpa batas (kosong = tidak diubah)
+}
+
+type GuestBookModerationLogResponse struct {
+	models.GuestBookModerationLog
+	UserName string `json:"user_name"` // Kosong jika aksi otomatis oleh sistem
+}
+
+// currentUserID mengambil ID admin yang login (untuk log moderasi)
+func currentUserID(c *gin.Context) *uint {
+	if value, ok := c.Get("userID"); ok {
+		if id, ok := value.(uint); ok {
+			return &id
+		}
+	}
+	return nil
+}
+
+// moderateGuestBookMessage menentukan status awal ucapan baru dan kata terlarang di dalamnya.
+// Ucapan yang ditandai selalu menunggu moderasi, walaupun auto-approve aktif.
+func moderateGuestBookMessage(wedding models.Wedding, message string) (string, []string) {
+	flagged := services.FindBlockedWords(message, services.ParseBlocklist(wedding.GuestBookBlocklist))
+	if wedding.GuestBookAutoApprove && len(flagged) == 0 {
+		return models.GuestBookApproved, flagged
+	}
+	return models.GuestBookPending, flagged
+}
+
+// logGuestBookModeration menyimpan satu baris log moderasi
+func logGuestBookModeration(tx *gorm.DB, weddingID, guestBookID uint, userID *uint, action, from, to, note string) error {
+	return tx.Create(&models.GuestBookModerationLog{
+		WeddingID:   weddingID,
+		GuestBookID: guestBookID,
+		UserID:      userID,
+		Action:      action,
+		FromStatus:  from,
+		ToStatus:    to,
+		Note:        note,
+	}).Error
+}
+
+// GetGuestBookSettings mengambil pengaturan moderasi buku tamu
+func GetGuestBookSettings(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var wedding models.Wedding
+	if err := db.DB.Select("id", "guest_book_auto_approve", "guest_book_blocklist", "guest_book_message_limit").First(&wedding, weddingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"auto_approve":      wedding.GuestBookAutoApprove,
+		"blocklist":         services.ParseBlocklist(wedding.GuestBookBlocklist),
+		"default_blocklist": services.DefaultBlocklist,
+		"message_limit":     wedding.GuestBookMessageLimit,
+	})
+}
+
+// UpdateGuestBookSettings mengatur auto-approve & kata terlarang tambahan
+func UpdateGuestBookSettings(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GuestBookSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.MessageLimit != nil && *input.MessageLimit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message_limit cannot be negative"})
+		return
+	}
+	blocklist := services.ParseBlocklist(strings.Join(input.Blocklist, ","))
+
+	updates := map[string]interface{}{
+		"guest_book_auto_approve": input.AutoApprove,
+		"guest_book_blocklist":    strings.Join(blocklist, ", "),
+	}
+	if input.MessageLimit != nil {
+		updates["guest_book_message_limit"] = *input.MessageLimit
+	}
+	if err := db.DB.Model(&models.Wedding{ID: weddingID}).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update guestbook settings"})
+		return
+	}
+
+	var wedding models.Wedding
+	db.DB.Select("id", "guest_book_message_limit").First(&wedding, weddingID)
+	c.JSON(http.StatusOK, gin.H{
+		"auto_approve":  input.AutoApprove,
+		"blocklist":     blocklist,
+		"message_limit": wedding.GuestBookMessageLimit,
+	})
+}
+
+// GetGuestBookModerationLog mengambil riwayat moderasi (filter opsional ?guest_book_id=)
+func GetGuestBookModerationLog(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	query := db.DB.Table("guest_book_moderation_logs").
+		Select("guest_book_moderation_logs.*, COALESCE(users.name, '') AS user_name").
+		Joins("LEFT JOIN users ON users.id = guest_book_moderation_logs.user_id").
+		Where("guest_book_moderation_logs.wedding_id = ?", weddingID)
+	if entryID := c.Query("guest_book_id"); entryID != "" {
+		query = query.Where("guest_book_moderation_logs.guest_book_id = ?", entryID)
+	}
+
+	var logs []GuestBookModerationLogResponse
+	if err := query.Order("guest_book_moderation_logs.created_at DESC").Limit(500).Scan(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderation log"})
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GuestBookReplyInput struct {
+	Message string `json:"message" binding:"required"`
+}
+
+// guestBookReplies mengambil balasan untuk beberapa ucapan, dikelompokkan per ID ucapan
+func guestBookReplies(guestBookIDs []uint) (map[uint][]models.GuestBookReply, error) {
+	replies := make(map[uint][]models.GuestBookReply, len(guestBookIDs))
+	if len(guestBookIDs) == 0 {
+		return replies, nil
+	}
+
+	var rows []models.GuestBookReply
+	if err := db.DB.Where("guest_book_id IN ?", guestBookIDs).Order("created_at ASC, id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, reply := range rows {
+		replies[reply.GuestBookID] = append(replies[reply.GuestBookID], reply)
+	}
+	return replies, nil
+}
+
+// deleteGuestBookReplies menghapus balasan milik ucapan yang akan dihapus.
+// guestBookIDs bisa berupa slice ID atau subquery.
+func deleteGuestBookReplies(tx *gorm.DB, guestBookIDs interface{}) error {
+	return tx.Where("guest_book_id IN (?)", guestBookIDs).Delete(&models.GuestBookReply{}).Error
+}
+
+// findWeddingGuestBook mengambil ucapan milik wedding (via tamu)
+func findWeddingGuestBook(weddingID uint, guestBookID string) (models.GuestBook, error) {
+	var guestBook models.GuestBook
+	err := db.DB.Joins("JOIN guests ON guests.id = guest_books.guest_id").
+		Where("guests.wedding_id = ? AND guest_books.id = ?", weddingID, guestBookID).
+		First(&guestBook).Error
+	return guestBook, err
+}
+
+// findWeddingGuestBookReply mengambil balasan milik wedding (via ucapan & tamu)
+func findWeddingGuestBookReply(weddingID uint, replyID string) (models.GuestBookReply, error) {
+	var reply models.GuestBookReply
+	err := db.DB.Joins("JOIN guest_books ON guest_books.id = guest_book_replies.guest_book_id").
+		Joins("JOIN guests ON guests.id = guest_books.guest_id").
+		Where("guests.wedding_id = ? AND guest_book_replies.id = ?", weddingID, replyID).
+		First(&reply).Error
+	return reply, err
+}
+
+// --- Guestbook Reply (Admin) Handlers ---
+
+// CreateGuestBookReply menambahkan balasan mempelai pada satu ucapan.
+// Balasan hanya tampil di undangan jika ucapannya sudah approved.
+func CreateGuestBookReply(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GuestBookReplyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	message := strings.TrimSpace(input.Message)
+	if message == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reply message cannot be empty"})
+		return
+	}
+
+	guestBook, err := findWeddingGuestBook(weddingID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guestbook entry not found"})
+		return
+	}
+
+	reply := models.GuestBookReply{
+		GuestBookID: guestBook.ID,
+		UserID:      currentUserID(c),
+		Message:     message,
+	}
+	if err := db.DB.Create(&reply).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reply"})
+		return
+	}
+	c.JSON(http.StatusCreated, reply)
+}
+
+// UpdateGuestBookReply mengubah isi balasan
+func UpdateGuestBookReply(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input GuestBookReplyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	message := strings.TrimSpace(input.Message)
+	if message == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reply message cannot be empty"})
+		return
+	}
+
+	reply, err := findWeddingGuestBookReply(weddingID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reply not found"})
+		return
+	}
+
+	reply.Message = message
+	if err := db.DB.Save(&reply).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reply"})
+		return
+	}
+	c.JSON(http.StatusOK, reply)
+}
+
+// DeleteGuestBookReply menghapus balasan
+func DeleteGuestBookReply(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	reply, err := findWeddingGuestBookReply(weddingID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reply not found"})
+		return
+	}
+
+	if err := db.DB.Delete(&reply).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reply"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reply deleted"})
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Pilihan diet yang dikenali (untuk rekap katering)
+var allowedDiets = map[string]bool{
+	"":            true, // Tidak ada kebutuhan khusus
+	"vegetarian":  true,
+	"vegan":       true,
+	"halal":       true,
+	"no_pork":     true,
+	"no_seafood":  true,
+	"gluten_free": true,
+}
+
+type GuestMemberInput struct {
+	Name        string `json:"name" binding:"required"`
+	AgeCategory string `json:"age_category"`
+	Diet        string `json:"diet"`
+	Allergies   string `json:"allergies"`
+}
+
+// normalize merapikan input dan memvalidasi nilai kategori usia & diet
+func (in *GuestMemberInput) normalize() error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.AgeCategory = strings.ToLower(strings.TrimSpace(in.AgeCategory))
+	in.Diet = strings.ToLower(strings.TrimSpace(in.Diet))
+	in.Allergies = strings.TrimSpace(in.Allergies)
+
+	if in.Name == "" {
+		return errors.New("member name is required")
+	}
+	if in.AgeCategory == "" {
+		in.AgeCategory = models.AgeCategoryAdult
+	}
+	if in.AgeCategory != models.AgeCategoryAdult && in.AgeCategory != models.AgeCategoryChild {
+		return fmt.Errorf("invalid age_category %q (use 'adult' or 'child')", in.AgeCategory)
+	}
+	if !allowedDiets[in.Diet] {
+		return fmt.Errorf("invalid diet %q", in.Diet)
+	}
+	return nil
+}
+
+func (in GuestMemberInput) toModel(guestID uint) models.GuestMember {
+	return models.GuestMember{
+		GuestID:     guestID,
+		Name:        in.Name,
+		AgeCategory: in.AgeCategory,
+		Diet:        in.Diet,
+		Allergies:   in.Allergies,
+	}
+}
+
+// replaceGuestMembers mengganti seluruh anggota rombongan milik tamu (dipakai saat RSVP)
+// Harus dipanggil di dalam transaksi.
+func replaceGuestMembers(tx *gorm.DB, guestID uint, inputs []GuestMemberInput) error {
+	// Penempatan meja per anggota ikut terhapus karena anggotanya diganti
+	oldMemberIDs := tx.Model(&models.GuestMember{}).Select("id").Where("guest_id = ?", guestID)
+	if err := deleteSeatAssignmentsForMembers(tx, oldMemberIDs); err != nil {
+		return err
+	}
+	if err := tx.Where("guest_id = ?", guestID).Delete(&models.GuestMember{}).Error; err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	members := make([]models.GuestMember, 0, len(inputs))
+	for _, in := range inputs {
+		members = append(members, in.toModel(guestID))
+	}
+	return tx.Create(&members).Error
+}
+
+// findGuestForWedding memastikan tamu ada dan milik wedding yang sedang login
+func findGuestForWedding(guestID string, weddingID uint) (models.Guest, error) {
+	var guest models.Guest
+	err := db.DB.Where("id = ? AND wedding_id = ?", guestID, weddingID).First(&guest).Error
+	return guest, err
+}
+
+// --- Guest Member (Admin) Handlers ---
+
+// GetGuestMembers mengambil anggota rombongan dari satu tamu
+func GetGuestMembers(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	guest, err := findGuestForWedding(c.Param("id"), weddingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guest not found"})
+		return
+	}
+
+	var members []models.GuestMember
+	if err := db.DB.Where("guest_id = ?", guest.ID).Order("id ASC").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// errGuestQuotaReached menandai rombongan yang anggotanya sudah memenuhi kuota tamu
+var errGuestQuotaReached = errors.New("guest quota reached")
+
+// lockGuestMembers mengunci baris tamu sampai transaksi tx selesai, lalu menghitung anggota
+// rombongannya. Penambahan anggota & perubahan kuota yang paralel jadi dicek bergantian.
+func lockGuestMembers(tx *gorm.DB, guestID uint) (models.Guest, int64, error) {
+	var guest models.Guest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&guest, guestID).Error; err != nil {
+		return guest, 0, err
+	}
+	var count int64
+	err := tx.Model(&models.GuestMember{}).Where("guest_id = ?", guestID).Count(&count).Error
+	return guest, count, err
+}
+
+// CreateGuestMember menambah anggota rombongan (dibatasi oleh kuota tamu)
+func CreateGuestMember(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	guest, err := findGuestForWedding(c.Param("id"), weddingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guest not found"})
+		return
+	}
+
+	var input GuestMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member := input.toModel(guest.ID)
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		locked, count, err := lockGuestMembers(tx, guest.ID)
+		if err != nil {
+			return err
+		}
+		guest = locked
+		if int(count) >= guest.AttendanceQuota {
+			return errGuestQuotaReached
+		}
+		return tx.Create(&member).Error
+	})
+	if errors.Is(err, errGuestQuotaReached) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Guest quota reached (%d people)", guest.AttendanceQuota)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create member"})
+		return
+	}
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateGuestMember memperbarui data anggota rombongan
+func UpdateGuestMember(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	guest, err := findGuestForWedding(c.Param("id"), weddingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guest not found"})
+		return
+	}
+
+	var input GuestMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var member models.GuestMember
+	if err := db.DB.Where("id = ? AND guest_id = ?", c.Param("member_id"), guest.ID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	member.Name = input.Name
+	member.AgeCategory = input.AgeCategory
+	member.Diet = input.Diet
+	member.Allergies = input.Allergies
+
+	if err := db.DB.Save(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// DeleteGuestMember menghapus anggota rombongan
+func DeleteGuestMember(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	guest, err := findGuestForWedding(c.Param("id"), weddingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guest not found"})
+		return
+	}
+
+	var member models.GuestMember
+	if err := db.DB.Where("id = ? AND guest_id = ?", c.Param("member_id"), guest.ID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteSeatAssignmentsForMembers(tx, []uint{member.ID}); err != nil {
+			return err
+		}
+		return tx.Delete(&member).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete member"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member deleted"})
+}
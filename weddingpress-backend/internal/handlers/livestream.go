@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"weddingpress_backend/internal/models"
+)
+
+// Platform live streaming yang dikenali
+var liveStreamPlatforms = map[string]bool{
+	"youtube":     true,
+	"instagram":   true,
+	"zoom":        true,
+	"google_meet": true,
+	"facebook":    true,
+	"tiktok":      true,
+	"other":       true,
+}
+
+const (
+	defaultLiveStreamRevealMinutes = 60
+	maxLiveStreamRevealMinutes     = 7 * 24 * 60
+)
+
+// liveStreamSettings adalah pengaturan live streaming yang sudah divalidasi
+type liveStreamSettings struct {
+	URL           string
+	Platform      string
+	StartAt       *time.Time
+	Note          string
+	RevealMinutes int
+}
+
+func (s liveStreamSettings) apply(event *models.Event) {
+	event.LiveStreamURL = s.URL
+	event.LiveStreamPlatform = s.Platform
+	event.LiveStreamStartAt = s.StartAt
+	event.LiveStreamNote = s.Note
+	event.LiveStreamRevealMinutes = s.RevealMinutes
+}
+
+// detectLiveStreamPlatform menebak platform dari domain URL streaming
+func detectLiveStreamPlatform(host string) string {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	switch {
+	case host == "youtu.be" || strings.HasSuffix(host, "youtube.com"):
+		return "youtube"
+	case strings.HasSuffix(host, "instagram.com"):
+		return "instagram"
+	case strings.HasSuffix(host, "zoom.us"):
+		return "zoom"
+	case host == "meet.google.com":
+		return "google_meet"
+	case strings.HasSuffix(host, "facebook.com") || host == "fb.watch":
+		return "facebook"
+	case strings.HasSuffix(host, "tiktok.com"):
+		return "tiktok"
+	}
+	return "other"
+}
+
+// resolveLiveStream memvalidasi pengaturan live streaming dari input acara
+func (in *EventInput) resolveLiveStream(loc *time.Location) (liveStreamSettings, error) {
+	settings := liveStreamSettings{
+		URL:           strings.TrimSpace(in.LiveStreamURL),
+		Platform:      strings.ToLower(strings.TrimSpace(in.LiveStreamPlatform)),
+		Note:          strings.TrimSpace(in.LiveStreamNote),
+		RevealMinutes: defaultLiveStreamRevealMinutes,
+	}
+	if in.LiveStreamRevealMinutes != nil {
+		settings.RevealMinutes = *in.LiveStreamRevealMinutes
+	}
+	if settings.RevealMinutes < 0 || settings.RevealMinutes > maxLiveStreamRevealMinutes {
+		return settings, fmt.Errorf("live_stream_reveal_minutes must be between 0 and %d", maxLiveStreamRevealMinutes)
+	}
+
+	if settings.URL == "" {
+		// Tanpa URL, pengaturan lain tidak berarti
+		settings.Platform = ""
+		settings.Note = ""
+		return settings, nil
+	}
+
+	u, err := url.Parse(settings.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return settings, errors.New("live_stream_url must be a valid http(s) link")
+	}
+	if settings.Platform == "" {
+		settings.Platform = detectLiveStreamPlatform(u.Host)
+	}
+	if !liveStreamPlatforms[settings.Platform] {
+		return settings, fmt.Errorf("invalid live_stream_platform %q", settings.Platform)
+	}
+
+	if strings.TrimSpace(in.LiveStreamStartAt) != "" {
+		t, err := parseEventTimestamp(in.LiveStreamStartAt, loc)
+		if err != nil {
+			return settings, err
+		}
+		utc := t.UTC()
+		settings.StartAt = &utc
+	}
+	return settings, nil
+}
+
+// liveStreamWindow mengembalikan rentang waktu link streaming boleh ditampilkan:
+// dari RevealMinutes sebelum streaming dimulai sampai acara selesai
+// (atau akhir hari jika acara tidak punya waktu selesai)
+func liveStreamWindow(e models.Event) (time.Time, time.Time) {
+	start := e.StartAt
+	if e.LiveStreamStartAt != nil {
+		start = *e.LiveStreamStartAt
+	}
+	from := start.Add(-time.Duration(e.LiveStreamRevealMinutes) * time.Minute)
+
+	var until time.Time
+	if e.EndAt != nil {
+		until = *e.EndAt
+	} else {
+		local := e.StartAt.In(eventLocation(e))
+		year, month, day := local.Date()
+		until = time.Date(year, month, day+1, 0, 0, 0, 0, local.Location())
+	}
+	if until.Before(start) {
+		until = start
+	}
+	return from, until
+}
+
+// revealLiveStreams menyembunyikan link & catatan akses streaming, kecuali untuk tamu
+// yang RSVP virtual dan sudah masuk jendela waktu tampil.
+// Platform & jadwal tetap dikirim agar template bisa menampilkan info "segera tersedia".
+func revealLiveStreams(events []models.Event, guest models.Guest, now time.Time) {
+	for i := range events {
+		e := &events[i]
+		if e.LiveStreamURL == "" {
+			continue
+		}
+
+		from, until := liveStreamWindow(*e)
+		if guest.AttendanceMode == models.AttendanceVirtual {
+			e.LiveStreamRevealAt = &from
+		}
+		if guest.AttendanceMode == models.AttendanceVirtual && !now.Before(from) && now.Before(until) {
+			continue
+		}
+		e.LiveStreamURL = ""
+		e.LiveStreamNote = ""
+	}
+}
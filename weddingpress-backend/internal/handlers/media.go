@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+)
+
+// Tipe media yang dipakai di galeri & story
+const (
+	mediaTypeImage = "image"
+	mediaTypeVideo = "video"
+)
+
+// weddingMediaFolder adalah folder upload milik satu wedding
+func weddingMediaFolder(weddingID uint) string {
+	return fmt.Sprintf("weddingpress/wedding-%d", weddingID)
+}
+
+// mediaBelongsToWedding mengecek bahwa URL media adalah hasil upload wedding ini:
+// URL (atau varian gambarnya) harus tercatat di media_assets milik wedding tersebut
+func mediaBelongsToWedding(mediaURL string, weddingID uint) bool {
+	u, err := url.Parse(mediaURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	var count int64
+	err = db.DB.Model(&models.MediaAsset{}).
+		Where("wedding_id = ? AND (url = ? OR thumb_url = ? OR medium_url = ?)", weddingID, mediaURL, mediaURL, mediaURL).
+		Count(&count).Error
+	return err == nil && count > 0
+}
+
+// guessMediaType menebak tipe media dari URL (Cloudinary memakai /video/upload/ untuk video)
+func guessMediaType(mediaURL string) string {
+	lower := strings.ToLower(mediaURL)
+	if strings.Contains(lower, "/video/upload/") {
+		return mediaTypeVideo
+	}
+	for _, ext := range []string{".mp4", ".mov", ".webm", ".m4v"} {
+		if strings.HasSuffix(lower, ext) {
+			return mediaTypeVideo
+		}
+	}
+	return mediaTypeImage
+}
+
+// validateWeddingMedia memvalidasi URL & tipe media milik wedding.
+// URL kosong berarti tanpa media; tipe kosong ditebak dari URL. currentURL (media yang
+// sudah tersimpan di record) tetap diterima walaupun diupload sebelum media_assets ada.
+func validateWeddingMedia(mediaURL, mediaType *string, weddingID uint, currentURL string) error {
+	*mediaURL = strings.TrimSpace(*mediaURL)
+	*mediaType = strings.ToLower(strings.TrimSpace(*mediaType))
+	if *mediaURL == "" {
+		*mediaType = ""
+		return nil
+	}
+	if *mediaURL != currentURL && !mediaBelongsToWedding(*mediaURL, weddingID) {
+		return errors.New("media must be uploaded through this wedding's upload endpoint")
+	}
+	if *mediaType == "" {
+		*mediaType = guessMediaType(*mediaURL)
+	}
+	if *mediaType != mediaTypeImage && *mediaType != mediaTypeVideo {
+		return fmt.Errorf("invalid media_type %q (use 'image' or 'video')", *mediaType)
+	}
+	return nil
+}
@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+	"weddingpress_backend/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Upload baru belum tentu langsung dipakai (URL disimpan di request berikutnya),
+// jadi asset yang lebih muda dari masa tenggang tidak dianggap yatim
+const defaultMediaOrphanGrace = 24 * time.Hour
+
+// mediaReferenceSources adalah kolom yang menyimpan URL hasil upload.
+// WeddingColumn membatasi pencarian ke satu wedding; Condition (opsional) menyaring baris.
+var mediaReferenceSources = []struct {
+	Type          string
+	Table         string
+	Column        string
+	WeddingColumn string
+	Condition     string
+}{
+	{"wedding", "weddings", "cover_image_url", "id", ""},
+	{"wedding", "weddings", "music_url", "id", ""},
+	{"music_track", "music_tracks", "url", "wedding_id", ""},
+	{"groom_bride", "groom_brides", "groom_photo_url", "wedding_id", ""},
+	{"groom_bride", "groom_brides", "bride_photo_url", "wedding_id", ""},
+	{"story", "stories", "media_url", "wedding_id", ""},
+	{"gallery", "galleries", "file_url", "wedding_id", ""},
+	{"gallery", "galleries", "medium_url", "wedding_id", ""},
+	{"gallery", "galleries", "thumb_url", "wedding_id", ""},
+	{"gift_account", "gift_accounts", "qr_code_url", "wedding_id", ""},
+	// Foto tamu yang sudah di-approve dimiliki item galerinya
+	{"guest_photo", "guest_photos", "file_url", "wedding_id", "status = 'pending'"},
+}
+
+// mediaTextReferenceSources adalah kolom teks bebas (Markdown) yang bisa menyematkan URL
+// hasil upload di mana saja, misal ![foto](url) di deskripsi story
+var mediaTextReferenceSources = []struct {
+	Type          string
+	Table         string
+	Column        string
+	WeddingColumn string
+}{
+	{"story", "stories", "description", "wedding_id"},
+}
+
+// MediaPurgeFailure adalah asset yang gagal dihapus saat purge
+type MediaPurgeFailure struct {
+	ID    uint   `json:"id"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// mediaOrphanGrace membaca masa tenggang dari MEDIA_ORPHAN_GRACE_HOURS (default 24 jam)
+func mediaOrphanGrace() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("MEDIA_ORPHAN_GRACE_HOURS")); err == nil && n >= 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return defaultMediaOrphanGrace
+}
+
+// mediaAssetFromUpload membuat catatan asset dari hasil upload
+func mediaAssetFromUpload(weddingID uint, upload services.UploadedFile) models.MediaAsset {
+	asset := models.MediaAsset{
+		WeddingID:   weddingID,
+		Provider:    upload.Provider,
+		Key:         upload.Key,
+		URL:         upload.URL,
+		ContentType: upload.ContentType,
+		Category:    upload.Category,
+		Size:        upload.Size,
+		Width:       upload.Width,
+		Height:      upload.Height,
+	}
+	if upload.Thumb != nil {
+		asset.ThumbKey, asset.ThumbURL = upload.Thumb.Key, upload.Thumb.URL
+		asset.Size += upload.Thumb.Size
+	}
+	if upload.Medium != nil {
+		asset.MediumKey, asset.MediumURL = upload.Medium.Key, upload.Medium.URL
+		asset.Size += upload.Medium.Size
+	}
+	return asset
+}
+
+// recordMediaAssets mencatat hasil upload sebagai MediaAsset. Kuota dicek ulang dengan baris
+// wedding dikunci, jadi upload paralel tidak bisa sama-sama lolos cek kuota di awal handler;
+// jika tidak muat dikembalikan *services.UploadError (413) dan pemanggil wajib membuang upload-nya.
+func recordMediaAssets(tx *gorm.DB, weddingID uint, uploads ...services.UploadedFile) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+	assets := make([]models.MediaAsset, len(uploads))
+	var size int64
+	for i, upload := range uploads {
+		assets[i] = mediaAssetFromUpload(weddingID, upload)
+		size += assets[i].Size
+	}
+	return tx.Transaction(func(tx *gorm.DB) error {
+		usage, err := lockStorageUsage(tx, weddingID)
+		if err != nil {
+			return err
+		}
+		if err := usage.CheckQuota(size); err != nil {
+			return err
+		}
+		return tx.Create(&assets).Error
+	})
+}
+
+// assetURLs mengembalikan semua URL (file utama & varian) milik asset
+func assetURLs(asset models.MediaAsset) []string {
+	urls := []string{asset.URL}
+	for _, u := range []string{asset.ThumbURL, asset.MediumURL} {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// loadMediaReferences memetakan URL -> record wedding yang memakainya
+func loadMediaReferences(weddingID uint) (map[string][]models.MediaReference, error) {
+	refs := make(map[string][]models.MediaReference)
+	for _, src := range mediaReferenceSources {
+		var rows []struct {
+			ID  uint
+			URL string
+		}
+		query := db.DB.Table(src.Table).
+			Select(fmt.Sprintf("id, %s AS url", src.Column)).
+			Where(fmt.Sprintf("%s = ? AND COALESCE(%s, '') <> ''", src.WeddingColumn, src.Column), weddingID)
+		if src.Condition != "" {
+			query = query.Where(src.Condition)
+		}
+		err := query.Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			refs[row.URL] = append(refs[row.URL], models.MediaReference{Type: src.Type, ID: row.ID, Field: src.Column})
+		}
+	}
+
+	// URL asset (utama maupun varian) yang muncul di dalam teks dianggap dipakai
+	for _, src := range mediaTextReferenceSources {
+		var rows []struct {
+			ID  uint
+			URL string
+		}
+		err := db.DB.Raw(fmt.Sprintf(`SELECT t.id, u.url FROM %s t
+			JOIN (
+				SELECT url FROM media_assets WHERE wedding_id = @wedding
+				UNION SELECT thumb_url FROM media_assets WHERE wedding_id = @wedding
+				UNION SELECT medium_url FROM media_assets WHERE wedding_id = @wedding
+			) u ON u.url <> '' AND strpos(t.%s, u.url) > 0
+			WHERE t.%s = @wedding`, src.Table, src.Column, src.WeddingColumn),
+			sql.Named("wedding", weddingID)).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			refs[row.URL] = append(refs[row.URL], models.MediaReference{Type: src.Type, ID: row.ID, Field: src.Column})
+		}
+	}
+	return refs, nil
+}
+
+// attachMediaReferences mengisi References setiap asset (URL utama maupun varian)
+func attachMediaReferences(assets []models.MediaAsset, refs map[string][]models.MediaReference) {
+	for i := range assets {
+		assets[i].References = []models.MediaReference{}
+		for _, u := range assetURLs(assets[i]) {
+			assets[i].References = append(assets[i].References, refs[u]...)
+		}
+	}
+}
+
+// deleteMediaAssetFiles menghapus file asset (beserta varian) dari storage
+func deleteMediaAssetFiles(asset models.MediaAsset) error {
+	store, err := storage.Get()
+	if err != nil {
+		return err
+	}
+	if asset.Provider != store.Name() {
+		return fmt.Errorf("asset is stored with the %s driver but %s is active", asset.Provider, store.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, key := range []string{asset.Key, asset.ThumbKey, asset.MediumKey} {
+		if key == "" {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeMediaAssets menghapus file asset dari storage lalu catatannya dari database.
+// Asset yang gagal dihapus dari storage tetap dicatat agar bisa dicoba lagi.
+func purgeMediaAssets(assets []models.MediaAsset) ([]models.MediaAsset, []MediaPurgeFailure) {
+	var deleted []models.MediaAsset
+	var failed []MediaPurgeFailure
+	for _, asset := range assets {
+		err := deleteMediaAssetFiles(asset)
+		if err == nil {
+			err = db.DB.Delete(&asset).Error
+		}
+		if err != nil {
+			log.Printf("Failed to purge media asset %d (%s): %v", asset.ID, asset.Key, err)
+			failed = append(failed, MediaPurgeFailure{ID: asset.ID, URL: asset.URL, Error: err.Error()})
+			continue
+		}
+		deleted = append(deleted, asset)
+	}
+	return deleted, failed
+}
+
+// unreferencedAssets memilih asset yang tidak dipakai record mana pun
+func unreferencedAssets(assets []models.MediaAsset, refs map[string][]models.MediaReference) []models.MediaAsset {
+	attachMediaReferences(assets, refs)
+	orphans := make([]models.MediaAsset, 0, len(assets))
+	for _, asset := range assets {
+		if len(asset.References) == 0 {
+			orphans = append(orphans, asset)
+		}
+	}
+	return orphans
+}
+
+// findMediaOrphans mengambil asset wedding yang tidak dipakai dan sudah lewat masa tenggang
+func findMediaOrphans(weddingID uint) ([]models.MediaAsset, error) {
+	var assets []models.MediaAsset
+	if err := db.DB.Where("wedding_id = ? AND created_at < ?", weddingID, time.Now().Add(-mediaOrphanGrace())).
+		Order("created_at ASC").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	refs, err := loadMediaReferences(weddingID)
+	if err != nil {
+		return nil, err
+	}
+	return unreferencedAssets(assets, refs), nil
+}
+
+// releaseMediaAssets menghapus asset dengan URL tertentu jika sudah tidak dipakai lagi
+// (dipanggil setelah record yang memakainya dihapus). Kegagalan hanya dicatat di log;
+// sisa file tetap bisa dibersihkan lewat purge orphan.
+func releaseMediaAssets(weddingID uint, urls ...string) {
+	var candidates []string
+	for _, u := range urls {
+		if u != "" {
+			candidates = append(candidates, u)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	var assets []models.MediaAsset
+	if err := db.DB.Where("wedding_id = ? AND (url IN ? OR thumb_url IN ? OR medium_url IN ?)",
+		weddingID, candidates, candidates, candidates).Find(&assets).Error; err != nil {
+		log.Printf("Failed to look up media assets to release: %v", err)
+		return
+	}
+	if len(assets) == 0 {
+		return
+	}
+	refs, err := loadMediaReferences(weddingID)
+	if err != nil {
+		log.Printf("Failed to load media references: %v", err)
+		return
+	}
+	purgeMediaAssets(unreferencedAssets(assets, refs))
+}
+
+// --- Media Asset (Admin) Handlers ---
+
+// GetMediaAssets mengambil semua asset wedding beserta record yang memakainya
+func GetMediaAssets(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var assets []models.MediaAsset
+	if err := db.DB.Where("wedding_id = ?", weddingID).Order("created_at DESC").Find(&assets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media assets"})
+		return
+	}
+	refs, err := loadMediaReferences(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media references"})
+		return
+	}
+
+	attachMediaReferences(assets, refs)
+	c.JSON(http.StatusOK, assets)
+}
+
+// GetMediaOrphans melaporkan asset yang tidak dipakai record mana pun
+func GetMediaOrphans(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	orphans, err := findMediaOrphans(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find orphaned media"})
+		return
+	}
+
+	var totalSize int64
+	for _, asset := range orphans {
+		totalSize += asset.Size
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":        len(orphans),
+		"total_size":   totalSize,
+		"grace_period": mediaOrphanGrace().String(),
+		"assets":       orphans,
+	})
+}
+
+// PurgeMediaOrphans menghapus semua asset yatim dari storage dan database.
+// Tanpa ?confirm=true hanya melaporkan asset yang akan dihapus (dry run).
+func PurgeMediaOrphans(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	orphans, err := findMediaOrphans(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find orphaned media"})
+		return
+	}
+
+	if confirm, _ := strconv.ParseBool(c.Query("confirm")); !confirm {
+		var totalSize int64
+		for _, asset := range orphans {
+			totalSize += asset.Size
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run":    true,
+			"count":      len(orphans),
+			"total_size": totalSize,
+			"assets":     orphans,
+		})
+		return
+	}
+
+	deleted, failed := purgeMediaAssets(orphans)
+	var freed int64
+	for _, asset := range deleted {
+		freed += asset.Size
+	}
+
+	status := http.StatusOK
+	if len(failed) > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"deleted":     len(deleted),
+		"freed_bytes": freed,
+		"failed":      failed,
+	})
+}
+
+// DeleteMediaAsset menghapus satu asset; ditolak jika masih dipakai
+func DeleteMediaAsset(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var asset models.MediaAsset
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&asset).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media asset not found"})
+		return
+	}
+	refs, err := loadMediaReferences(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media references"})
+		return
+	}
+
+	assets := []models.MediaAsset{asset}
+	attachMediaReferences(assets, refs)
+	if len(assets[0].References) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Media asset is still in use", "references": assets[0].References})
+		return
+	}
+
+	if _, failed := purgeMediaAssets(assets); len(failed) > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media asset"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Media asset deleted"})
+}
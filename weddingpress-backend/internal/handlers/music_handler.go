@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultMusicVolume = 0.8
+
+// InvitationMusic adalah musik latar yang dikirim ke AudioPlayer di undangan publik
+type InvitationMusic struct {
+	URL         string  `json:"url"`
+	Title       string  `json:"title"`
+	Artist      string  `json:"artist"`
+	Duration    float64 `json:"duration"`     // Detik (0 jika tidak diketahui)
+	StartOffset float64 `json:"start_offset"` // Detik
+	Loop        bool    `json:"loop"`
+	Volume      float64 `json:"volume"` // 0.0 - 1.0
+}
+
+type MusicTrackInput struct {
+	Title  string `json:"title" binding:"required"`
+	Artist string `json:"artist"`
+}
+
+type MusicSettingsInput struct {
+	TrackID     *uint    `json:"track_id"` // null = tidak memakai lagu dari pustaka
+	StartOffset float64  `json:"start_offset"`
+	Loop        *bool    `json:"loop"`   // Default true
+	Volume      *float64 `json:"volume"` // Default 0.8
+}
+
+// invitationMusic menyusun musik latar undangan. Metadata lagu hanya dipakai jika
+// MusicURL masih menunjuk ke lagu pustaka yang dipilih.
+func invitationMusic(wedding models.Wedding) *InvitationMusic {
+	if wedding.MusicURL == "" {
+		return nil
+	}
+
+	music := &InvitationMusic{
+		URL:         wedding.MusicURL,
+		StartOffset: wedding.MusicStartOffset,
+		Loop:        wedding.MusicLoop,
+		Volume:      wedding.MusicVolume,
+	}
+	if wedding.MusicTrackID != nil {
+		var track models.MusicTrack
+		if err := db.DB.Where("id = ? AND wedding_id = ?", *wedding.MusicTrackID, wedding.ID).First(&track).Error; err == nil &&
+			track.URL == wedding.MusicURL {
+			music.Title = track.Title
+			music.Artist = track.Artist
+			music.Duration = track.Duration
+		}
+	}
+	return music
+}
+
+// readAudioMetadata membaca durasi & tag dari file audio yang di-upload
+func readAudioMetadata(open services.FileOpener, contentType string) services.AudioMetadata {
+	src, err := open()
+	if err != nil {
+		return services.AudioMetadata{}
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return services.AudioMetadata{}
+	}
+	return services.ParseAudioMetadata(data, contentType)
+}
+
+// --- Music (Admin) Handlers ---
+
+// GetMusicTracks mengambil pustaka musik wedding
+func GetMusicTracks(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var tracks []models.MusicTrack
+	if err := db.DB.Where("wedding_id = ?", weddingID).Order("created_at DESC").Find(&tracks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch music tracks"})
+		return
+	}
+	c.JSON(http.StatusOK, tracks)
+}
+
+// UploadMusicTrack meng-upload file audio (field "file") ke pustaka musik.
+// Judul & artis diambil dari tag file; field form "title"/"artist" (opsional) menimpanya.
+func UploadMusicTrack(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxUploadBytes()+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded. Make sure the field name is 'file'"})
+		return
+	}
+
+	usage, err := weddingStorageUsage(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check storage quota"})
+		return
+	}
+	if err := usage.CheckQuota(file.Size); err != nil {
+		writeUploadError(c, err)
+		return
+	}
+
+	object, err := services.UploadFile(file, weddingMediaFolder(weddingID), services.PurposeMusic)
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+
+	meta := readAudioMetadata(file.Open, object.ContentType)
+	track := models.MusicTrack{
+		WeddingID:   weddingID,
+		URL:         object.URL,
+		Title:       meta.Title,
+		Artist:      meta.Artist,
+		Album:       meta.Album,
+		Duration:    meta.Duration,
+		ContentType: object.ContentType,
+		Size:        object.Size,
+	}
+	if title := strings.TrimSpace(c.PostForm("title")); title != "" {
+		track.Title = title
+	}
+	if artist := strings.TrimSpace(c.PostForm("artist")); artist != "" {
+		track.Artist = artist
+	}
+	if track.Title == "" {
+		track.Title = strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordMediaAssets(tx, weddingID, object); err != nil {
+			return err
+		}
+		return tx.Create(&track).Error
+	})
+	if err != nil {
+		discardUploads(object)
+		var uploadErr *services.UploadError
+		if errors.As(err, &uploadErr) {
+			writeUploadError(c, err)
+			return
+		}
+		log.Printf("Failed to save music track: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save music track"})
+		return
+	}
+	c.JSON(http.StatusCreated, track)
+}
+
+// UpdateMusicTrack mengubah judul/artis lagu
+func UpdateMusicTrack(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input MusicTrackInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var track models.MusicTrack
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&track).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Music track not found"})
+		return
+	}
+
+	track.Title = strings.TrimSpace(input.Title)
+	track.Artist = strings.TrimSpace(input.Artist)
+	if err := db.DB.Save(&track).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update music track"})
+		return
+	}
+	c.JSON(http.StatusOK, track)
+}
+
+// DeleteMusicTrack menghapus lagu dari pustaka (dan dari undangan jika sedang dipakai)
+func DeleteMusicTrack(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var track models.MusicTrack
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&track).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Music track not found"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Wedding{}).Where("id = ? AND music_track_id = ?", weddingID, track.ID).
+			Updates(map[string]interface{}{
+				"music_track_id": nil,
+				"music_url":      gorm.Expr("CASE WHEN music_url = ? THEN '' ELSE music_url END", track.URL),
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&track).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete music track"})
+		return
+	}
+
+	// File di storage ikut dihapus jika tidak dipakai record lain
+	releaseMediaAssets(weddingID, track.URL)
+	c.JSON(http.StatusOK, gin.H{"message": "Music track deleted"})
+}
+
+// GetMusicSettings mengambil lagu terpilih & preferensi pemutar
+func GetMusicSettings(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var wedding models.Wedding
+	if err := db.DB.First(&wedding, weddingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var track *models.MusicTrack
+	if wedding.MusicTrackID != nil {
+		var selected models.MusicTrack
+		if err := db.DB.Where("id = ? AND wedding_id = ?", *wedding.MusicTrackID, weddingID).First(&selected).Error; err == nil {
+			track = &selected
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"track_id":     wedding.MusicTrackID,
+		"track":        track,
+		"music_url":    wedding.MusicURL,
+		"start_offset": wedding.MusicStartOffset,
+		"loop":         wedding.MusicLoop,
+		"volume":       wedding.MusicVolume,
+	})
+}
+
+// UpdateMusicSettings memilih lagu dari pustaka dan mengatur offset, loop & volume
+func UpdateMusicSettings(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input MusicSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var wedding models.Wedding
+	if err := db.DB.First(&wedding, weddingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	loop := true
+	if input.Loop != nil {
+		loop = *input.Loop
+	}
+	volume := defaultMusicVolume
+	if input.Volume != nil {
+		volume = *input.Volume
+	}
+	if volume < 0 || volume > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "volume must be between 0 and 1"})
+		return
+	}
+	if input.StartOffset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_offset cannot be negative"})
+		return
+	}
+
+	musicURL := wedding.MusicURL
+	if input.TrackID != nil {
+		var track models.MusicTrack
+		if err := db.DB.Where("id = ? AND wedding_id = ?", *input.TrackID, weddingID).First(&track).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Music track not found"})
+			return
+		}
+		if track.Duration > 0 && input.StartOffset >= track.Duration {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_offset must be shorter than the track duration"})
+			return
+		}
+		musicURL = track.URL
+	} else if wedding.MusicTrackID != nil {
+		// Lagu pustaka dilepas: hentikan juga musiknya jika URL masih milik lagu tersebut
+		var previous models.MusicTrack
+		if err := db.DB.First(&previous, *wedding.MusicTrackID).Error; err == nil && previous.URL == musicURL {
+			musicURL = ""
+		}
+	}
+
+	wedding.MusicTrackID = input.TrackID
+	wedding.MusicURL = musicURL
+	wedding.MusicStartOffset = input.StartOffset
+	wedding.MusicLoop = loop
+	wedding.MusicVolume = volume
+	// Select memaksa nilai kosong (false, 0, NULL) ikut disimpan
+	if err := db.DB.Model(&wedding).
+		Select("MusicTrackID", "MusicURL", "MusicStartOffset", "MusicLoop", "MusicVolume").
+		Updates(&wedding).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update music settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Music settings updated",
+		"music":   invitationMusic(wedding),
+	})
+}
@@ -1,21 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"weddingpress_backend/internal/db"
 	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // InvitationData adalah struct gabungan untuk respons JSON
 type InvitationData struct {
-	Guest   models.Guest   `json:"guest"`
-	Wedding models.Wedding `json:"wedding"` // Termasuk semua relasi (GroomBride, Events, dll)
+	Guest    models.Guest      `json:"guest"`
+	Greeting string            `json:"greeting"` // Sapaan kustom dari grup tamu (kosong jika tidak ada)
+	Wedding  models.Wedding    `json:"wedding"`  // Termasuk semua relasi (GroomBride, Events, dll)
+	Seating  []GuestSeat       `json:"seating"`  // Meja tamu di setiap acara (kosong jika belum diatur)
+	Schedule services.Schedule `json:"schedule"` // Countdown & status acara, dihitung di zona waktu wedding
+	Music    *InvitationMusic  `json:"music"`    // Musik latar & preferensi pemutar (null jika tidak ada)
 }
 
 // GetInvitationBySlug adalah handler publik utama
@@ -24,8 +32,12 @@ func GetInvitationBySlug(c *gin.Context) {
 
 	var guest models.Guest
 	// 1. Cari tamu berdasarkan slug
-	// Kita juga preload GuestBook milik tamu ini
-	if err := db.DB.Preload("GuestBook").Where("slug = ?", slug).First(&guest).Error; err != nil {
+	// Kita juga preload ucapan (beserta balasan) & anggota rombongan milik tamu ini
+	if err := db.DB.Preload("GuestBooks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("guest_books.created_at ASC")
+	}).Preload("GuestBooks.Replies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("guest_book_replies.created_at ASC")
+	}).Preload("Members").Preload("Answers").Preload("GuestGroup").Where("slug = ?", slug).First(&guest).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Undangan tidak ditemukan"})
 			return
@@ -38,29 +50,114 @@ func GetInvitationBySlug(c *gin.Context) {
 	// 2. Ambil data Wedding terkait, dan PRELOAD semua relasi
 	if err := db.DB.
 		Preload("GroomBride").
-		Preload("Events").
+		Preload("Events", visibleEventsScope(guest)). // Hanya acara yang boleh dilihat tamu ini
 		Preload("Stories", func(db *gorm.DB) *gorm.DB {
-			return db.Order("stories.\"order\" ASC") // Urutkan story
+			return db.Order("stories.\"order\" ASC, stories.id ASC") // Urutkan story
+		}).
+		// Item di dalam album hanya dikirim di GalleryAlbums.Items; Galleries berisi item
+		// tanpa album saja (bucket "unsorted") agar tidak ada item yang terkirim dua kali
+		Preload("Galleries", func(tx *gorm.DB) *gorm.DB {
+			return orderedAlbumItems(tx.Where("galleries.album_id IS NULL"))
+		}).
+		Preload("GalleryAlbums", orderedAlbums).
+		Preload("GalleryAlbums.Items", orderedAlbumItems).
+		Preload("GiftAccounts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("gift_accounts.\"order\" ASC, gift_accounts.id ASC")
+		}).
+		Preload("RSVPQuestions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("rsvp_questions.\"order\" ASC, rsvp_questions.id ASC")
 		}).
-		Preload("Galleries").
-		Preload("GiftAccounts"). // <-- TAMBAHKAN PRELOAD INI
 		First(&wedding, guest.WeddingID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Data pernikahan tidak ditemukan"})
 		return
 	}
 
-	// 3. Gabungkan data
+	attachDirections(wedding.Events)
+	attachAlbumCovers(wedding.GalleryAlbums)
+	for i := range wedding.Stories {
+		wedding.Stories[i].DescriptionHTML = services.RenderMarkdown(wedding.Stories[i].Description)
+	}
+	revealLiveStreams(wedding.Events, guest, time.Now())
+
+	// 3. Ambil info meja tamu
+	seating, err := getGuestSeats(guest.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data tempat duduk"})
+		return
+	}
+
+	// 4. Gabungkan data
 	data := InvitationData{
-		Guest:   guest,
-		Wedding: wedding,
+		Guest:    guest,
+		Greeting: guestGreeting(guest),
+		Wedding:  wedding,
+		Seating:  seating,
+		Schedule: weddingSchedule(wedding, time.Now()),
+		Music:    invitationMusic(wedding),
 	}
 
 	c.JSON(http.StatusOK, data)
 }
 
+// visibleEventsScope membatasi acara yang boleh dilihat tamu:
+// 1. Aturan audiens acara (semua tamu / grup tertentu / tamu tertentu), dan
+// 2. Pengaturan grup tamu (jika grup memilih acara tertentu, hanya acara tersebut).
+func visibleEventsScope(guest models.Guest) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		invitedGuests := db.DB.Table("event_audience_guests").Select("event_id").Where("guest_id = ?", guest.ID)
+		audience := db.DB.Where("events.audience = ? OR events.audience IS NULL OR events.audience = ''", models.EventAudienceAll).
+			Or("events.audience = ? AND events.id IN (?)", models.EventAudienceGuests, invitedGuests)
+		if guest.GroupID != nil {
+			invitedGroups := db.DB.Table("event_audience_groups").Select("event_id").Where("guest_group_id = ?", *guest.GroupID)
+			audience = audience.Or("events.audience = ? AND events.id IN (?)", models.EventAudienceGroups, invitedGroups)
+		}
+		tx = tx.Where(audience)
+
+		if guest.GroupID != nil {
+			groupEvents := db.DB.Table("guest_group_events").Select("event_id").Where("guest_group_id = ?", *guest.GroupID)
+			tx = tx.Where("NOT EXISTS (SELECT 1 FROM guest_group_events WHERE guest_group_id = ?) OR events.id IN (?)", *guest.GroupID, groupEvents)
+		}
+		return tx.Order("events.start_at ASC")
+	}
+}
+
+// weddingSchedule menghitung jadwal (countdown, acara berikutnya, status) di zona waktu wedding
+func weddingSchedule(wedding models.Wedding, now time.Time) services.Schedule {
+	loc, err := services.LoadTimezone(wedding.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	events := make([]services.ScheduleEvent, 0, len(wedding.Events))
+	for _, e := range wedding.Events {
+		events = append(events, services.ScheduleEvent{
+			ID:    e.ID,
+			Name:  e.Name,
+			Start: e.StartAt.In(eventLocation(e)), // Akhir hari default dihitung di zona waktu acara
+			End:   e.EndAt,
+		})
+	}
+	return services.ComputeSchedule(events, now, loc)
+}
+
+// guestGreeting mengisi placeholder {name} pada sapaan grup tamu
+func guestGreeting(guest models.Guest) string {
+	if guest.GuestGroup == nil {
+		return ""
+	}
+	return strings.ReplaceAll(guest.GuestGroup.Greeting, "{name}", guest.Name)
+}
+
 // Struct untuk input RSVP
 type RSVPInput struct {
 	TotalAttendance int `json:"total_attendance"`
+	// Opsional: daftar nama yang hadir. Jika diisi, TotalAttendance mengikuti jumlah anggota.
+	Members []GuestMemberInput `json:"members"`
+	// Jawaban untuk pertanyaan RSVP kustom milik wedding
+	Answers []RSVPAnswerInput `json:"answers"`
+	// "in_person", "virtual" (menonton live streaming) atau "declined".
+	// Jika kosong, ditentukan dari TotalAttendance.
+	AttendanceMode string `json:"attendance_mode"`
 }
 
 // PostRSVP untuk tamu mengkonfirmasi kehadiran
@@ -79,15 +176,84 @@ func PostRSVP(c *gin.Context) {
 		return
 	}
 
-	// Update data tamu
-	guest.IsRSVP = true
+	// Validasi anggota rombongan (jika dikirim)
+	for i := range input.Members {
+		if err := input.Members[i].normalize(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Anggota #%d tidak valid: %v", i+1, err)})
+			return
+		}
+	}
+	if input.Members != nil {
+		input.TotalAttendance = len(input.Members)
+	}
+
 	// Pastikan jumlah tamu tidak negatif
 	if input.TotalAttendance < 0 {
 		input.TotalAttendance = 0
 	}
+
+	// Tentukan cara hadir. Tamu virtual & yang tidak hadir tidak dihitung di lokasi.
+	switch strings.TrimSpace(input.AttendanceMode) {
+	case "":
+		input.AttendanceMode = models.AttendanceDeclined
+		if input.TotalAttendance > 0 {
+			input.AttendanceMode = models.AttendanceInPerson
+		}
+	case models.AttendanceInPerson:
+		input.AttendanceMode = models.AttendanceInPerson
+		if input.TotalAttendance == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah tamu yang hadir minimal 1 orang"})
+			return
+		}
+	case models.AttendanceVirtual, models.AttendanceDeclined:
+		input.AttendanceMode = strings.TrimSpace(input.AttendanceMode)
+		input.TotalAttendance = 0
+		input.Members = []GuestMemberInput{} // Anggota rombongan hanya untuk yang hadir langsung
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pilihan kehadiran tidak valid (in_person, virtual atau declined)"})
+		return
+	}
+	// Pastikan jumlah tamu tidak melebihi kuota undangan
+	if input.TotalAttendance > guest.AttendanceQuota {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Jumlah tamu melebihi kuota undangan (maks. %d orang)", guest.AttendanceQuota)})
+		return
+	}
+
+	// Validasi jawaban pertanyaan kustom terhadap pertanyaan milik wedding tamu ini
+	var questions []models.RSVPQuestion
+	if err := db.DB.Where("wedding_id = ?", guest.WeddingID).Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memuat pertanyaan RSVP"})
+		return
+	}
+	var existing []models.RSVPAnswer
+	if err := db.DB.Where("guest_id = ?", guest.ID).Find(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memuat jawaban RSVP"})
+		return
+	}
+	answers, questionIDs, err := validateRSVPAnswers(questions, input.Answers, existing, input.TotalAttendance > 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update data tamu
+	guest.IsRSVP = true
 	guest.TotalAttendance = input.TotalAttendance
+	guest.AttendanceMode = input.AttendanceMode
 
-	if err := db.DB.Save(&guest).Error; err != nil {
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&guest).Error; err != nil {
+			return err
+		}
+		// Anggota hanya diganti jika tamu mengirim daftar anggota
+		if input.Members != nil {
+			if err := replaceGuestMembers(tx, guest.ID, input.Members); err != nil {
+				return err
+			}
+		}
+		return replaceRSVPAnswers(tx, guest.ID, questionIDs, answers)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan RSVP"})
 		return
 	}
@@ -95,6 +261,9 @@ func PostRSVP(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "RSVP berhasil disimpan"})
 }
 
+// errGuestBookLimitReached menandai tamu yang sudah mencapai batas ucapan
+var errGuestBookLimitReached = errors.New("guest book message limit reached")
+
 // Struct untuk input GuestBook
 type GuestBookInput struct {
 	Message string `json:"message" binding:"required"`
@@ -117,51 +286,93 @@ func PostGuestBook(c *gin.Context) {
 		return
 	}
 
-	// Buat entri GuestBook baru
+	var wedding models.Wedding
+	if err := db.DB.Select("id", "guest_book_auto_approve", "guest_book_blocklist", "guest_book_message_limit").
+		First(&wedding, guest.WeddingID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data pernikahan tidak ditemukan"})
+		return
+	}
+
+	// Status awal: approved jika auto-approve aktif & tidak ada kata terlarang
+	status, flaggedWords := moderateGuestBookMessage(wedding, input.Message)
+	note := "auto-approved"
+	if len(flaggedWords) > 0 {
+		note = "flagged: " + strings.Join(flaggedWords, ", ")
+	} else if status == models.GuestBookPending {
+		note = ""
+	}
+
 	guestBook := models.GuestBook{
-		GuestID: guest.ID,
-		Message: input.Message,
-		Status:  "pending", // Admin harus approve
-	}
-
-	// Cek jika sudah pernah post, update saja (Upsert)
-	// GORM akan otomatis update jika ada konflik di GuestID (karena unique)
-	// Kita gunakan Save() yang bertindak sebagai Upsert untuk struct
-	if err := db.DB.Save(&guestBook).Error; err != nil {
-		// Jika error bukan karena duplikat, tampilkan error
-		if !strings.Contains(err.Error(), "duplicate key") {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan ucapan"})
-			return
+		GuestID:      guest.ID,
+		Message:      input.Message,
+		Status:       status,
+		Flagged:      len(flaggedWords) > 0,
+		FlaggedWords: strings.Join(flaggedWords, ", "),
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Batas ucapan per tamu. Ucapan yang ditolak/disembunyikan admin tidak dihitung, agar
+		// kiriman orang lain ke guest_id ini tidak menghabiskan jatah tamu (spam dibatasi rate limit).
+		// Baris tamu dikunci agar kiriman paralel tidak sama-sama lolos hitungan.
+		if limit := wedding.GuestBookMessageLimit; limit > 0 {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Guest{}, guest.ID).Error; err != nil {
+				return err
+			}
+			var count int64
+			if err := tx.Model(&models.GuestBook{}).Where("guest_id = ? AND status NOT IN ?", guest.ID,
+				[]string{models.GuestBookRejected, models.GuestBookHidden}).Count(&count).Error; err != nil {
+				return err
+			}
+			if count >= int64(limit) {
+				return errGuestBookLimitReached
+			}
 		}
-		// Jika duplikat, kita update saja pesan dan set status kembali ke pending
-		guestBook.Status = "pending"
-		if err := db.DB.Where("guest_id = ?", guest.ID).Updates(&guestBook).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui ucapan"})
-			return
+		if err := tx.Create(&guestBook).Error; err != nil {
+			return err
 		}
+		return logGuestBookModeration(tx, wedding.ID, guestBook.ID, nil, moderationSubmitted, "", status, note)
+	})
+	if errors.Is(err, errGuestBookLimitReached) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("Batas ucapan per tamu (%d ucapan) sudah tercapai", wedding.GuestBookMessageLimit)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan ucapan"})
+		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Ucapan berhasil dikirim, menunggu persetujuan"})
+	if status == models.GuestBookApproved {
+		c.JSON(http.StatusCreated, gin.H{"message": "Ucapan berhasil dikirim", "id": guestBook.ID, "status": status})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Ucapan berhasil dikirim, menunggu persetujuan", "id": guestBook.ID, "status": status})
 }
 
-// GetGuestBook mengambil ucapan yang sudah "approved"
+// GetGuestBook mengambil ucapan yang sudah "approved" beserta balasan mempelai
 func GetGuestBook(c *gin.Context) {
 	weddingID := c.Param("wedding_id")
 
-	type GuestBookResponse struct {
-		GuestName string    `json:"guest_name"`
+	type GuestBookReplyResponse struct {
+		ID        uint      `json:"id"`
 		Message   string    `json:"message"`
 		CreatedAt time.Time `json:"created_at"`
 	}
 
+	type GuestBookResponse struct {
+		ID        uint                     `json:"id"`
+		GuestName string                   `json:"guest_name"`
+		Message   string                   `json:"message"`
+		CreatedAt time.Time                `json:"created_at"`
+		Replies   []GuestBookReplyResponse `gorm:"-" json:"replies"`
+	}
+
 	var results []GuestBookResponse
 
 	// Query ini join tabel guest_books dengan guests
 	// lalu filter berdasarkan wedding_id DAN status 'approved'
 	err := db.DB.Table("guest_books").
-		Select("guests.name as guest_name, guest_books.message, guest_books.created_at").
+		Select("guest_books.id, guests.name as guest_name, guest_books.message, guest_books.created_at").
 		Joins("JOIN guests ON guests.id = guest_books.guest_id").
-		Where("guests.wedding_id = ? AND guest_books.status = ?", weddingID, "approved").
+		Where("guests.wedding_id = ? AND guest_books.status = ?", weddingID, models.GuestBookApproved).
 		Order("guest_books.created_at DESC").
 		Scan(&results).Error
 
@@ -170,5 +381,26 @@ func GetGuestBook(c *gin.Context) {
 		return
 	}
 
+	// Balasan mempelai ditampilkan di bawah ucapannya (urut dari yang terlama)
+	ids := make([]uint, len(results))
+	for i, entry := range results {
+		ids[i] = entry.ID
+	}
+	replies, err := guestBookReplies(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil ucapan"})
+		return
+	}
+	for i := range results {
+		results[i].Replies = []GuestBookReplyResponse{}
+		for _, reply := range replies[results[i].ID] {
+			results[i].Replies = append(results[i].Replies, GuestBookReplyResponse{
+				ID:        reply.ID,
+				Message:   reply.Message,
+				CreatedAt: reply.CreatedAt,
+			})
+		}
+	}
+
 	c.JSON(http.StatusOK, results)
 }
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// weddingStorageUsage menghitung pemakaian storage wedding dari MediaAsset yang tercatat
+func weddingStorageUsage(weddingID uint) (services.StorageUsage, error) {
+	return storageUsage(db.DB, weddingID)
+}
+
+// lockStorageUsage mengunci baris wedding (SELECT ... FOR UPDATE) sampai transaksi tx selesai,
+// lalu menghitung pemakaiannya. Upload paralel wedding yang sama jadi dicek bergantian.
+func lockStorageUsage(tx *gorm.DB, weddingID uint) (services.StorageUsage, error) {
+	return storageUsage(tx.Clauses(clause.Locking{Strength: "UPDATE"}), weddingID)
+}
+
+func storageUsage(tx *gorm.DB, weddingID uint) (services.StorageUsage, error) {
+	var wedding models.Wedding
+	if err := tx.Select("id", "plan").First(&wedding, weddingID).Error; err != nil {
+		return services.StorageUsage{}, err
+	}
+
+	var used int64
+	if err := tx.Session(&gorm.Session{NewDB: true}).Model(&models.MediaAsset{}).Where("wedding_id = ?", weddingID).
+		Select("COALESCE(SUM(size), 0)").Scan(&used).Error; err != nil {
+		return services.StorageUsage{}, err
+	}
+
+	plan := services.NormalizePlan(wedding.Plan)
+	return services.StorageUsage{
+		Plan:       plan,
+		UsedBytes:  used,
+		LimitBytes: services.StorageQuota(plan),
+	}, nil
+}
+
+// reserveStorageQuota membagi sisa kuota ke file sesuai urutan di form.
+// Elemen ke-i berisi kesalahan kuota jika file ke-i tidak muat (nil jika muat).
+func reserveStorageQuota(usage services.StorageUsage, files []*multipart.FileHeader) []error {
+	errs := make([]error, len(files))
+	for i, file := range files {
+		if err := usage.CheckQuota(file.Size); err != nil {
+			errs[i] = err
+			continue
+		}
+		usage.UsedBytes += file.Size
+	}
+	return errs
+}
+
+// GetStorageUsage menampilkan pemakaian storage & batas kuota paket wedding
+func GetStorageUsage(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	usage, err := weddingStorageUsage(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch storage usage"})
+		return
+	}
+
+	var byCategory []struct {
+		Category string `json:"category"`
+		Count    int64  `json:"count"`
+		Bytes    int64  `json:"bytes"`
+	}
+	if err := db.DB.Model(&models.MediaAsset{}).Where("wedding_id = ?", weddingID).
+		Select("category, COUNT(*) AS count, COALESCE(SUM(size), 0) AS bytes").
+		Group("category").Order("category").Scan(&byCategory).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch storage usage"})
+		return
+	}
+
+	response := gin.H{
+		"plan":            usage.Plan,
+		"used_bytes":      usage.UsedBytes,
+		"limit_bytes":     usage.LimitBytes,
+		"remaining_bytes": usage.RemainingBytes(),
+		"unlimited":       usage.Unlimited(),
+		"by_category":     byCategory,
+	}
+	if !usage.Unlimited() {
+		response["used_percent"] = float64(usage.UsedBytes) * 100 / float64(usage.LimitBytes)
+	}
+	c.JSON(http.StatusOK, response)
+}
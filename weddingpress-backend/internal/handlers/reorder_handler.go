@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"weddingpress_backend/internal/db"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReorderInput adalah urutan baru hasil drag-and-drop: semua ID item, dari atas ke bawah
+type ReorderInput struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+// reorderRows menulis ulang kolom "order" (0, 1, 2, ...) sesuai urutan ids.
+// ids harus berisi tepat semua item dalam scope (misal: milik wedding) agar tidak ada urutan yang bentrok.
+func reorderRows(table string, scope func(*gorm.DB) *gorm.DB, ids []uint) error {
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return errReorder(fmt.Sprintf("duplicate id %d", id))
+		}
+		seen[id] = true
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		// Kunci baris agar dua reorder bersamaan tidak saling menimpa sebagian
+		if err := tx.Table(table).Scopes(scope).
+			Clauses(clause.Locking{Strength: "UPDATE"}).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) != len(ids) {
+			return errReorder(fmt.Sprintf("expected all %d items, got %d", len(existing), len(ids)))
+		}
+		for _, id := range existing {
+			if !seen[id] {
+				return errReorder(fmt.Sprintf("item %d is missing from the new order", id))
+			}
+		}
+
+		for position, id := range ids {
+			if err := tx.Table(table).Scopes(scope).Where("id = ?", id).
+				Update("order", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// weddingScope membatasi query ke baris milik satu wedding
+func weddingScope(weddingID uint) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("wedding_id = ?", weddingID)
+	}
+}
+
+// nextOrder mengembalikan nilai "order" untuk item baru agar muncul di paling akhir.
+// tx adalah db.DB atau transaksi yang sedang berjalan.
+func nextOrder(tx *gorm.DB, table string, scope func(*gorm.DB) *gorm.DB) int {
+	var maxOrder *int
+	tx.Table(table).Scopes(scope).Select(`MAX("order")`).Scan(&maxOrder)
+	if maxOrder == nil {
+		return 0
+	}
+	return *maxOrder + 1
+}
+
+// errReorder adalah kesalahan input reorder (dikembalikan sebagai 400)
+type errReorder string
+
+func (e errReorder) Error() string { return string(e) }
+
+// handleReorder adalah handler bersama untuk endpoint reorder
+func handleReorder(c *gin.Context, table, label string) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input ReorderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeReorderResult(c, reorderRows(table, weddingScope(weddingID), input.IDs), input.IDs, label)
+}
+
+// writeReorderResult mengirim respons reorder (kesalahan input = 400)
+func writeReorderResult(c *gin.Context, err error, ids []uint, label string) {
+	if err != nil {
+		var inputErr errReorder
+		if errors.As(err, &inputErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder " + label})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Order updated", "ids": ids})
+}
+
+// ReorderStories menyimpan urutan story sekaligus
+func ReorderStories(c *gin.Context) {
+	handleReorder(c, "stories", "stories")
+}
+
+// ReorderGalleryInput adalah urutan item di satu album (album_id kosong = item tanpa album)
+type ReorderGalleryInput struct {
+	IDs     []uint `json:"ids" binding:"required"`
+	AlbumID *uint  `json:"album_id"`
+}
+
+// ReorderGallery menyimpan urutan item galeri di dalam satu album sekaligus
+func ReorderGallery(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input ReorderGalleryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeReorderResult(c, reorderRows("galleries", galleryAlbumScope(weddingID, input.AlbumID), input.IDs), input.IDs, "gallery")
+}
+
+// ReorderGalleryAlbums menyimpan urutan album galeri sekaligus
+func ReorderGalleryAlbums(c *gin.Context) {
+	handleReorder(c, "gallery_albums", "gallery albums")
+}
+
+// ReorderGiftAccounts menyimpan urutan rekening hadiah sekaligus
+func ReorderGiftAccounts(c *gin.Context) {
+	handleReorder(c, "gift_accounts", "gift accounts")
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// GuestReport adalah rekap kehadiran untuk katering & tempat duduk
+type GuestReport struct {
+	TotalGuests     int `json:"total_guests"`     // Jumlah undangan (baris Guest)
+	RSVPCount       int `json:"rsvp_count"`       // Sudah konfirmasi
+	DeclinedCount   int `json:"declined_count"`   // Konfirmasi tapi tidak hadir (0 orang)
+	VirtualCount    int `json:"virtual_count"`    // Menonton live streaming
+	PendingCount    int `json:"pending_count"`    // Belum konfirmasi
+	TotalQuota      int `json:"total_quota"`      // Total kuota semua undangan
+	TotalAttendance int `json:"total_attendance"` // Total orang yang akan hadir
+
+	// Rincian berdasarkan anggota rombongan yang sudah diisi
+	NamedMembers    int            `json:"named_members"`
+	Adults          int            `json:"adults"`
+	Children        int            `json:"children"`
+	UnnamedAttendee int            `json:"unnamed_attendees"` // Hadir tapi belum diisi namanya
+	Diets           map[string]int `json:"diets"`
+	WithAllergies   int            `json:"with_allergies"`
+}
+
+// buildGuestReport menghitung rekap dari daftar tamu (Members harus sudah di-preload)
+func buildGuestReport(guests []models.Guest) GuestReport {
+	report := GuestReport{Diets: map[string]int{}}
+
+	for _, guest := range guests {
+		report.TotalGuests++
+		report.TotalQuota += guest.AttendanceQuota
+
+		if !guest.IsRSVP {
+			report.PendingCount++
+			continue
+		}
+		report.RSVPCount++
+		if guest.AttendanceMode == models.AttendanceVirtual {
+			report.VirtualCount++
+			continue
+		}
+		if guest.TotalAttendance == 0 {
+			report.DeclinedCount++
+			continue
+		}
+		report.TotalAttendance += guest.TotalAttendance
+
+		for _, member := range guest.Members {
+			report.NamedMembers++
+			if member.AgeCategory == models.AgeCategoryChild {
+				report.Children++
+			} else {
+				report.Adults++
+			}
+			if member.Diet != "" {
+				report.Diets[member.Diet]++
+			}
+			if member.Allergies != "" {
+				report.WithAllergies++
+			}
+		}
+		if unnamed := guest.TotalAttendance - len(guest.Members); unnamed > 0 {
+			report.UnnamedAttendee += unnamed
+		}
+	}
+
+	return report
+}
+
+// GetGuestReport mengembalikan rekap kehadiran tamu & anggota rombongan
+func GetGuestReport(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var guests []models.Guest
+	if err := db.DB.Preload("Members").Where("wedding_id = ?", weddingID).Find(&guests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildGuestReport(guests))
+}
+
+// ExportGuests mengekspor daftar tamu & anggota rombongan ke file Excel
+func ExportGuests(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var guests []models.Guest
+	if err := db.DB.Preload("Members").Where("wedding_id = ?", weddingID).Order("name ASC").Find(&guests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guests"})
+		return
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	// Sheet 1: Tamu (satu baris per undangan)
+	const guestSheet = "Tamu"
+	f.SetSheetName("Sheet1", guestSheet)
+	f.SetSheetRow(guestSheet, "A1", &[]interface{}{"Nama", "Grup", "Slug", "Kuota", "RSVP", "Kehadiran", "Jumlah Hadir", "Anggota Terdaftar"})
+	for i, guest := range guests {
+		rsvp := "Belum"
+		if guest.IsRSVP {
+			rsvp = "Sudah"
+		}
+		mode := map[string]string{
+			models.AttendanceInPerson: "Hadir",
+			models.AttendanceVirtual:  "Virtual",
+			models.AttendanceDeclined: "Tidak Hadir",
+		}[guest.AttendanceMode]
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		f.SetSheetRow(guestSheet, cell, &[]interface{}{
+			guest.Name, guest.Group, guest.Slug, guest.AttendanceQuota, rsvp, mode, guest.TotalAttendance, len(guest.Members),
+		})
+	}
+
+	// Sheet 2: Anggota (satu baris per orang)
+	const memberSheet = "Anggota"
+	f.NewSheet(memberSheet)
+	f.SetSheetRow(memberSheet, "A1", &[]interface{}{"Nama Tamu", "Grup", "Nama Anggota", "Kategori Usia", "Diet", "Alergi"})
+	row := 2
+	for _, guest := range guests {
+		for _, member := range guest.Members {
+			cell, _ := excelize.CoordinatesToCellName(1, row)
+			f.SetSheetRow(memberSheet, cell, &[]interface{}{
+				guest.Name, guest.Group, member.Name, member.AgeCategory, member.Diet, member.Allergies,
+			})
+			row++
+		}
+	}
+
+	filename := fmt.Sprintf("guests-%s.xlsx", time.Now().Format("20060102"))
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := f.Write(c.Writer); err != nil {
+		log.Printf("Failed to write guest export: %v", err)
+	}
+}
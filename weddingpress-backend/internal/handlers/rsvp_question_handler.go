@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RSVPQuestionInput struct {
+	Question string   `json:"question" binding:"required"`
+	Type     string   `json:"type" binding:"required"` // text, single_choice, multi_choice, number
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+	Order    int      `json:"order"`
+}
+
+func isChoiceQuestion(questionType string) bool {
+	return questionType == models.QuestionTypeSingleChoice || questionType == models.QuestionTypeMultiChoice
+}
+
+// normalize memvalidasi tipe pertanyaan dan merapikan daftar pilihan
+func (in *RSVPQuestionInput) normalize() error {
+	in.Question = strings.TrimSpace(in.Question)
+	in.Type = strings.ToLower(strings.TrimSpace(in.Type))
+	if in.Question == "" {
+		return errors.New("question is required")
+	}
+
+	switch in.Type {
+	case models.QuestionTypeText, models.QuestionTypeNumber:
+		in.Options = nil // Pilihan tidak relevan
+		return nil
+	case models.QuestionTypeSingleChoice, models.QuestionTypeMultiChoice:
+		// lanjut validasi pilihan di bawah
+	default:
+		return fmt.Errorf("invalid question type %q", in.Type)
+	}
+
+	seen := map[string]bool{}
+	options := make([]string, 0, len(in.Options))
+	for _, opt := range in.Options {
+		opt = strings.TrimSpace(opt)
+		if opt == "" || seen[opt] {
+			continue
+		}
+		seen[opt] = true
+		options = append(options, opt)
+	}
+	if len(options) < 2 {
+		return errors.New("choice questions need at least 2 distinct options")
+	}
+	in.Options = options
+	return nil
+}
+
+// --- RSVP Question (Admin) Handlers ---
+
+// GetRSVPQuestions mengambil semua pertanyaan RSVP kustom milik wedding
+func GetRSVPQuestions(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var questions []models.RSVPQuestion
+	if err := db.DB.Where("wedding_id = ?", weddingID).Order("\"order\" ASC, id ASC").Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+func CreateRSVPQuestion(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input RSVPQuestionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question := models.RSVPQuestion{
+		WeddingID: weddingID,
+		Question:  input.Question,
+		Type:      input.Type,
+		Options:   input.Options,
+		Required:  input.Required,
+		Order:     input.Order,
+	}
+
+	if err := db.DB.Create(&question).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create question"})
+		return
+	}
+	c.JSON(http.StatusCreated, question)
+}
+
+func UpdateRSVPQuestion(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input RSVPQuestionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var question models.RSVPQuestion
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&question).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+
+	question.Question = input.Question
+	question.Type = input.Type
+	question.Options = input.Options
+	question.Required = input.Required
+	question.Order = input.Order
+
+	if err := db.DB.Save(&question).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update question"})
+		return
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+// DeleteRSVPQuestion menghapus pertanyaan beserta semua jawabannya
+func DeleteRSVPQuestion(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var question models.RSVPQuestion
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&question).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", question.ID).Delete(&models.RSVPAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&question).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete question"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Question deleted"})
+}
+
+// --- Validasi & penyimpanan jawaban (dipakai oleh PostRSVP) ---
+
+type RSVPAnswerInput struct {
+	QuestionID uint     `json:"question_id"`
+	Value      string   `json:"value"`   // Untuk text & number
+	Choices    []string `json:"choices"` // Untuk single/multi choice
+}
+
+// answerMatchesQuestion memastikan jawaban lama masih cocok dengan tipe & pilihan
+// pertanyaan saat ini (pertanyaan bisa diubah admin setelah tamu menjawab)
+func answerMatchesQuestion(q models.RSVPQuestion, a models.RSVPAnswer) bool {
+	switch q.Type {
+	case models.QuestionTypeText:
+		return a.Value != "" && len(a.Choices) == 0
+	case models.QuestionTypeNumber:
+		_, err := strconv.ParseFloat(a.Value, 64)
+		return err == nil && len(a.Choices) == 0
+	}
+
+	if len(a.Choices) == 0 || (q.Type == models.QuestionTypeSingleChoice && len(a.Choices) > 1) {
+		return false
+	}
+	allowed := make(map[string]bool, len(q.Options))
+	for _, opt := range q.Options {
+		allowed[opt] = true
+	}
+	for _, choice := range a.Choices {
+		if !allowed[choice] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRSVPAnswers mencocokkan jawaban dengan pertanyaan milik wedding dan mengembalikan
+// jawaban baru beserta ID pertanyaan yang dikirim (termasuk yang dikosongkan).
+// Pertanyaan wajib hanya dipaksa jika tamu hadir (attending = true); jawaban lama (existing)
+// yang masih valid tetap dihitung untuk pertanyaan yang tidak dikirim ulang.
+func validateRSVPAnswers(questions []models.RSVPQuestion, inputs []RSVPAnswerInput, existing []models.RSVPAnswer, attending bool) ([]models.RSVPAnswer, []uint, error) {
+	byID := make(map[uint]models.RSVPQuestion, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	submitted := map[uint]bool{}
+	answered := map[uint]bool{}
+	answers := make([]models.RSVPAnswer, 0, len(inputs))
+	for _, in := range inputs {
+		q, ok := byID[in.QuestionID]
+		if !ok {
+			return nil, nil, fmt.Errorf("pertanyaan #%d tidak ditemukan", in.QuestionID)
+		}
+		if submitted[q.ID] {
+			return nil, nil, fmt.Errorf("pertanyaan \"%s\" dijawab lebih dari sekali", q.Question)
+		}
+		submitted[q.ID] = true
+
+		answer := models.RSVPAnswer{QuestionID: q.ID}
+		switch q.Type {
+		case models.QuestionTypeText:
+			answer.Value = strings.TrimSpace(in.Value)
+			if len(answer.Value) > 1000 {
+				return nil, nil, fmt.Errorf("jawaban \"%s\" terlalu panjang", q.Question)
+			}
+		case models.QuestionTypeNumber:
+			answer.Value = strings.TrimSpace(in.Value)
+			if answer.Value != "" {
+				if _, err := strconv.ParseFloat(answer.Value, 64); err != nil {
+					return nil, nil, fmt.Errorf("jawaban \"%s\" harus berupa angka", q.Question)
+				}
+			}
+		default: // single_choice & multi_choice
+			allowed := map[string]bool{}
+			for _, opt := range q.Options {
+				allowed[opt] = true
+			}
+			picked := map[string]bool{}
+			for _, choice := range in.Choices {
+				choice = strings.TrimSpace(choice)
+				if !allowed[choice] {
+					return nil, nil, fmt.Errorf("pilihan \"%s\" tidak valid untuk \"%s\"", choice, q.Question)
+				}
+				if !picked[choice] {
+					picked[choice] = true
+					answer.Choices = append(answer.Choices, choice)
+				}
+			}
+			if q.Type == models.QuestionTypeSingleChoice && len(answer.Choices) > 1 {
+				return nil, nil, fmt.Errorf("\"%s\" hanya boleh memilih satu jawaban", q.Question)
+			}
+		}
+
+		if answer.Value == "" && len(answer.Choices) == 0 {
+			continue // Jawaban kosong diperlakukan sebagai tidak dijawab
+		}
+		answered[q.ID] = true
+		answers = append(answers, answer)
+	}
+
+	// Jawaban lama yang tidak dikirim ulang tetap berlaku selama masih valid
+	for _, a := range existing {
+		if q, ok := byID[a.QuestionID]; ok && !submitted[q.ID] && answerMatchesQuestion(q, a) {
+			answered[q.ID] = true
+		}
+	}
+
+	if attending {
+		for _, q := range questions {
+			if q.Required && !answered[q.ID] {
+				return nil, nil, fmt.Errorf("pertanyaan \"%s\" wajib diisi", q.Question)
+			}
+		}
+	}
+
+	questionIDs := make([]uint, 0, len(submitted))
+	for id := range submitted {
+		questionIDs = append(questionIDs, id)
+	}
+	return answers, questionIDs, nil
+}
+
+// replaceRSVPAnswers mengganti jawaban tamu untuk pertanyaan yang dikirim saja (questionIDs);
+// jawaban pertanyaan lain tidak disentuh. Harus dipanggil di dalam transaksi.
+func replaceRSVPAnswers(tx *gorm.DB, guestID uint, questionIDs []uint, answers []models.RSVPAnswer) error {
+	if len(questionIDs) == 0 {
+		return nil
+	}
+	if err := tx.Where("guest_id = ? AND question_id IN ?", guestID, questionIDs).Delete(&models.RSVPAnswer{}).Error; err != nil {
+		return err
+	}
+	if len(answers) == 0 {
+		return nil
+	}
+	for i := range answers {
+		answers[i].GuestID = guestID
+	}
+	return tx.Create(&answers).Error
+}
+
+// --- Rekap jawaban ---
+
+type TextAnswer struct {
+	GuestName string `json:"guest_name"`
+	Value     string `json:"value"`
+}
+
+type QuestionSummary struct {
+	models.RSVPQuestion
+	AnswerCount int            `json:"answer_count"`
+	ChoiceCount map[string]int `json:"choice_counts,omitempty"` // single/multi choice
+	Sum         *float64       `json:"sum,omitempty"`           // number
+	Average     *float64       `json:"average,omitempty"`       // number
+	Min         *float64       `json:"min,omitempty"`           // number
+	Max         *float64       `json:"max,omitempty"`           // number
+	Texts       []TextAnswer   `json:"texts,omitempty"`         // text
+}
+
+type AllergyNote struct {
+	GuestName  string `json:"guest_name"`
+	MemberName string `json:"member_name"`
+	Allergies  string `json:"allergies"`
+}
+
+type RSVPSummary struct {
+	Questions []QuestionSummary `json:"questions"`
+	Dietary   struct {
+		Diets         map[string]int `json:"diets"`
+		WithAllergies int            `json:"with_allergies"`
+		Allergies     []AllergyNote  `json:"allergies"`
+	} `json:"dietary"`
+}
+
+func summarizeQuestion(q models.RSVPQuestion, answers []models.RSVPAnswer, guestNames map[uint]string) QuestionSummary {
+	summary := QuestionSummary{RSVPQuestion: q}
+	if isChoiceQuestion(q.Type) {
+		summary.ChoiceCount = make(map[string]int, len(q.Options))
+		for _, opt := range q.Options {
+			summary.ChoiceCount[opt] = 0
+		}
+	}
+
+	var sum, lo, hi float64
+	numbers := 0
+	for _, a := range answers {
+		if !answerMatchesQuestion(q, a) {
+			continue // Jawaban lama yang tidak cocok lagi setelah pertanyaan diubah
+		}
+		summary.AnswerCount++
+		switch q.Type {
+		case models.QuestionTypeText:
+			summary.Texts = append(summary.Texts, TextAnswer{GuestName: guestNames[a.GuestID], Value: a.Value})
+		case models.QuestionTypeNumber:
+			n, err := strconv.ParseFloat(a.Value, 64)
+			if err != nil {
+				continue
+			}
+			if numbers == 0 || n < lo {
+				lo = n
+			}
+			if numbers == 0 || n > hi {
+				hi = n
+			}
+			sum += n
+			numbers++
+		default:
+			for _, choice := range a.Choices {
+				summary.ChoiceCount[choice]++
+			}
+		}
+	}
+
+	if q.Type == models.QuestionTypeNumber && numbers > 0 {
+		avg := sum / float64(numbers)
+		summary.Sum, summary.Average, summary.Min, summary.Max = &sum, &avg, &lo, &hi
+	}
+	return summary
+}
+
+// GetRSVPSummary merekap jawaban pertanyaan kustom dan kebutuhan diet tamu yang hadir
+func GetRSVPSummary(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var questions []models.RSVPQuestion
+	if err := db.DB.Where("wedding_id = ?", weddingID).Order("\"order\" ASC, id ASC").Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch questions"})
+		return
+	}
+
+	var guests []models.Guest
+	if err := db.DB.Preload("Members").Preload("Answers").Where("wedding_id = ? AND is_rsvp = ?", weddingID, true).Find(&guests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guests"})
+		return
+	}
+
+	guestNames := make(map[uint]string, len(guests))
+	answersByQuestion := map[uint][]models.RSVPAnswer{}
+	for _, g := range guests {
+		guestNames[g.ID] = g.Name
+		for _, a := range g.Answers {
+			answersByQuestion[a.QuestionID] = append(answersByQuestion[a.QuestionID], a)
+		}
+	}
+
+	var summary RSVPSummary
+	summary.Questions = make([]QuestionSummary, 0, len(questions))
+	for _, q := range questions {
+		summary.Questions = append(summary.Questions, summarizeQuestion(q, answersByQuestion[q.ID], guestNames))
+	}
+
+	// Kebutuhan diet hanya dihitung dari tamu yang hadir
+	report := buildGuestReport(guests)
+	summary.Dietary.Diets = report.Diets
+	summary.Dietary.WithAllergies = report.WithAllergies
+	summary.Dietary.Allergies = []AllergyNote{}
+	for _, g := range guests {
+		if g.TotalAttendance == 0 {
+			continue
+		}
+		for _, m := range g.Members {
+			if m.Allergies != "" {
+				summary.Dietary.Allergies = append(summary.Dietary.Allergies, AllergyNote{GuestName: g.Name, MemberName: m.Name, Allergies: m.Allergies})
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
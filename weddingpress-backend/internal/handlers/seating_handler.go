@@ -0,0 +1,623 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
+	"weddingpress_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeatingTableInput struct {
+	EventID  uint   `json:"event_id" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Capacity int    `json:"capacity" binding:"required,min=1"`
+}
+
+type SeatAssignInput struct {
+	GuestID  uint  `json:"guest_id" binding:"required"`
+	MemberID *uint `json:"member_id"` // Opsional: hanya satu anggota rombongan
+}
+
+type AutoAssignInput struct {
+	EventID  uint  `json:"event_id" binding:"required"`
+	RSVPOnly *bool `json:"rsvp_only"` // Default true: hanya tamu yang sudah konfirmasi hadir
+}
+
+// guestSeatCount menghitung kursi yang dibutuhkan satu rombongan tamu
+// (Members harus sudah di-preload)
+func guestSeatCount(guest models.Guest) int {
+	if len(guest.Members) > 0 {
+		return len(guest.Members)
+	}
+	if guest.IsRSVP {
+		return guest.TotalAttendance
+	}
+	return guest.AttendanceQuota
+}
+
+// assignmentSeats menghitung kursi yang dipakai oleh satu penempatan
+func assignmentSeats(a models.SeatAssignment, guests map[uint]models.Guest) int {
+	if a.GuestMemberID != nil {
+		return 1
+	}
+	return guestSeatCount(guests[a.GuestID])
+}
+
+// loadGuestsByID memuat tamu (beserta anggota) untuk daftar penempatan
+func loadGuestsByID(tx *gorm.DB, assignments []models.SeatAssignment) (map[uint]models.Guest, error) {
+	ids := make([]uint, 0, len(assignments))
+	for _, a := range assignments {
+		ids = append(ids, a.GuestID)
+	}
+	result := map[uint]models.Guest{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var guests []models.Guest
+	if err := tx.Preload("Members").Where("id IN ?", ids).Find(&guests).Error; err != nil {
+		return nil, err
+	}
+	for _, g := range guests {
+		result[g.ID] = g
+	}
+	return result, nil
+}
+
+// isUniqueViolation mengecek apakah error berasal dari unique index Postgres (kode 23505)
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// tableUsedSeats menghitung kursi terisi pada satu meja
+func tableUsedSeats(tx *gorm.DB, tableID uint) (int, error) {
+	var assignments []models.SeatAssignment
+	if err := tx.Where("table_id = ?", tableID).Find(&assignments).Error; err != nil {
+		return 0, err
+	}
+	guests, err := loadGuestsByID(tx, assignments)
+	if err != nil {
+		return 0, err
+	}
+
+	used := 0
+	for _, a := range assignments {
+		used += assignmentSeats(a, guests)
+	}
+	return used, nil
+}
+
+// deleteSeatAssignmentsForMembers menghapus penempatan milik anggota yang akan dihapus
+func deleteSeatAssignmentsForMembers(tx *gorm.DB, memberIDs interface{}) error {
+	return tx.Where("guest_member_id IN (?)", memberIDs).Delete(&models.SeatAssignment{}).Error
+}
+
+// --- Seating Table (Admin) Handlers ---
+
+// GetSeatingTables mengambil semua meja (bisa difilter per acara dengan ?event_id=)
+func GetSeatingTables(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	query := db.DB.Preload("Assignments").Where("wedding_id = ?", weddingID)
+	if eventID := c.Query("event_id"); eventID != "" {
+		query = query.Where("event_id = ?", eventID)
+	}
+
+	var tables []models.SeatingTable
+	if err := query.Order("event_id ASC, id ASC").Find(&tables).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tables"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tables)
+}
+
+func CreateSeatingTable(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input SeatingTableInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var event models.Event
+	if err := db.DB.Where("id = ? AND wedding_id = ?", input.EventID, weddingID).First(&event).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event not found"})
+		return
+	}
+
+	table := models.SeatingTable{
+		WeddingID: weddingID,
+		EventID:   event.ID,
+		Name:      strings.TrimSpace(input.Name),
+		Capacity:  input.Capacity,
+	}
+
+	if err := db.DB.Create(&table).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create table"})
+		return
+	}
+	c.JSON(http.StatusCreated, table)
+}
+
+func UpdateSeatingTable(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input SeatingTableInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var table models.SeatingTable
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&table).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+		return
+	}
+
+	used, err := tableUsedSeats(db.DB, table.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check table occupancy"})
+		return
+	}
+	if input.EventID != table.EventID && used > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot move a table with assigned guests to another event"})
+		return
+	}
+	if input.Capacity < used {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Capacity cannot be lower than assigned seats (%d)", used)})
+		return
+	}
+	if input.EventID != table.EventID {
+		var event models.Event
+		if err := db.DB.Where("id = ? AND wedding_id = ?", input.EventID, weddingID).First(&event).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Event not found"})
+			return
+		}
+	}
+
+	table.EventID = input.EventID
+	table.Name = strings.TrimSpace(input.Name)
+	table.Capacity = input.Capacity
+
+	if err := db.DB.Save(&table).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update table"})
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
+
+// DeleteSeatingTable menghapus meja beserta semua penempatannya
+func DeleteSeatingTable(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var table models.SeatingTable
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&table).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("table_id = ?", table.ID).Delete(&models.SeatAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&table).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete table"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Table deleted"})
+}
+
+// errSeating adalah error validasi penempatan (dikembalikan sebagai 400)
+type errSeating struct{ msg string }
+
+func (e errSeating) Error() string { return e.msg }
+
+// AssignSeat menempatkan tamu atau satu anggota rombongan di meja, dengan validasi kapasitas
+func AssignSeat(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input SeatAssignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var table models.SeatingTable
+	if err := db.DB.Where("id = ? AND wedding_id = ?", c.Param("id"), weddingID).First(&table).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+		return
+	}
+
+	var guest models.Guest
+	if err := db.DB.Preload("Members").Where("id = ? AND wedding_id = ?", input.GuestID, weddingID).First(&guest).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guest not found"})
+		return
+	}
+
+	assignment := models.SeatAssignment{
+		TableID:       table.ID,
+		EventID:       table.EventID,
+		GuestID:       guest.ID,
+		GuestMemberID: input.MemberID,
+	}
+
+	seats := guestSeatCount(guest)
+	if input.MemberID != nil {
+		seats = 1
+		found := false
+		for _, m := range guest.Members {
+			if m.ID == *input.MemberID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Member not found for this guest"})
+			return
+		}
+	}
+	if seats <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guest is not attending"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci meja (kapasitas) lalu tamu (penempatan yang sudah ada) agar penempatan
+		// paralel tidak sama-sama lolos pengecekan di bawah
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&table, table.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Guest{}, guest.ID).Error; err != nil {
+			return err
+		}
+
+		// Tamu tidak boleh punya dua penempatan yang tumpang tindih di acara yang sama:
+		// penempatan rombongan penuh tidak boleh dicampur dengan penempatan per anggota.
+		var existing []models.SeatAssignment
+		if err := tx.Where("event_id = ? AND guest_id = ?", table.EventID, guest.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, e := range existing {
+			if e.GuestMemberID == nil {
+				return errSeating{"Guest is already seated for this event"}
+			}
+			if input.MemberID == nil {
+				return errSeating{"Some members of this guest are already seated individually for this event"}
+			}
+			if *e.GuestMemberID == *input.MemberID {
+				return errSeating{"Member is already seated for this event"}
+			}
+		}
+
+		used, err := tableUsedSeats(tx, table.ID)
+		if err != nil {
+			return err
+		}
+		if used+seats > table.Capacity {
+			return errSeating{fmt.Sprintf("Table %s is full (%d/%d seats used, %d needed)", table.Name, used, table.Capacity, seats)}
+		}
+
+		return tx.Create(&assignment).Error
+	})
+
+	var seatingErr errSeating
+	if errors.As(err, &seatingErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": seatingErr.Error()})
+		return
+	}
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guest is already seated for this event"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign seat"})
+		return
+	}
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// UnassignSeat menghapus satu penempatan
+func UnassignSeat(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var assignment models.SeatAssignment
+	err = db.DB.Joins("JOIN seating_tables ON seating_tables.id = seat_assignments.table_id").
+		Where("seating_tables.wedding_id = ? AND seat_assignments.id = ?", weddingID, c.Param("id")).
+		First(&assignment).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Seat assignment not found"})
+		return
+	}
+
+	if err := db.DB.Delete(&assignment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete seat assignment"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Seat assignment deleted"})
+}
+
+// AutoAssignSeats menempatkan tamu yang belum punya meja secara otomatis berdasarkan Guest.Group
+func AutoAssignSeats(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	var input AutoAssignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rsvpOnly := input.RSVPOnly == nil || *input.RSVPOnly
+
+	var event models.Event
+	if err := db.DB.Where("id = ? AND wedding_id = ?", input.EventID, weddingID).First(&event).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var (
+		guests      []models.Guest
+		assignments []models.SeatAssignment
+		unplaced    []uint
+	)
+	// Baca pemakaian meja dan simpan hasilnya dalam satu transaksi; meja-meja acara dikunci
+	// agar AssignSeat atau auto-assign lain tidak mengisi kursi yang sama di tengah jalan
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		var tableIDs []uint
+		if err := tx.Model(&models.SeatingTable{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ?", event.ID).Order("id ASC").Pluck("id", &tableIDs).Error; err != nil {
+			return err
+		}
+		if len(tableIDs) == 0 {
+			return errSeating{"No tables defined for this event"}
+		}
+
+		var tables []models.SeatingTable
+		if err := tx.Preload("Assignments").Where("id IN ?", tableIDs).Order("id ASC").Find(&tables).Error; err != nil {
+			return err
+		}
+
+		guestQuery := tx.Preload("Members").Where("wedding_id = ?", weddingID)
+		if rsvpOnly {
+			guestQuery = guestQuery.Where("is_rsvp = ? AND total_attendance > 0", true)
+		}
+		if err := guestQuery.Order("id ASC").Find(&guests).Error; err != nil {
+			return err
+		}
+
+		// Hitung kursi terpakai & grup per meja, serta tamu yang sudah punya meja
+		seated := map[uint]bool{}
+		var allAssignments []models.SeatAssignment
+		for _, t := range tables {
+			allAssignments = append(allAssignments, t.Assignments...)
+		}
+		seatedGuests, err := loadGuestsByID(tx, allAssignments)
+		if err != nil {
+			return err
+		}
+
+		slots := make([]services.SeatingSlot, 0, len(tables))
+		for _, t := range tables {
+			slot := services.SeatingSlot{TableID: t.ID, Capacity: t.Capacity, Groups: map[string]int{}}
+			for _, a := range t.Assignments {
+				seats := assignmentSeats(a, seatedGuests)
+				slot.Used += seats
+				slot.Groups[seatedGuests[a.GuestID].Group] += seats
+				seated[a.GuestID] = true
+			}
+			slots = append(slots, slot)
+		}
+
+		var parties []services.SeatingParty
+		for _, g := range guests {
+			if seated[g.ID] {
+				continue
+			}
+			if seats := guestSeatCount(g); seats > 0 {
+				parties = append(parties, services.SeatingParty{GuestID: g.ID, Group: g.Group, Seats: seats})
+			}
+		}
+
+		var placed map[uint]uint
+		placed, unplaced = services.AutoAssignSeats(slots, parties)
+
+		assignments = make([]models.SeatAssignment, 0, len(placed))
+		for _, p := range parties {
+			if tableID, ok := placed[p.GuestID]; ok {
+				assignments = append(assignments, models.SeatAssignment{TableID: tableID, EventID: event.ID, GuestID: p.GuestID})
+			}
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+		return tx.Create(&assignments).Error
+	})
+	var seatingErr errSeating
+	if errors.As(err, &seatingErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": seatingErr.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save seat assignments"})
+		return
+	}
+	guestsByID := make(map[uint]models.Guest, len(guests))
+	for _, g := range guests {
+		guestsByID[g.ID] = g
+	}
+
+	unassigned := make([]gin.H, 0, len(unplaced))
+	for _, id := range unplaced {
+		g := guestsByID[id]
+		unassigned = append(unassigned, gin.H{"guest_id": g.ID, "name": g.Name, "group": g.Group, "seats": guestSeatCount(g)})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    fmt.Sprintf("%d guests assigned", len(assignments)),
+		"assigned":   assignments,
+		"unassigned": unassigned,
+	})
+}
+
+// --- Seating Chart ---
+
+type SeatingOccupant struct {
+	AssignmentID uint   `json:"assignment_id"`
+	GuestID      uint   `json:"guest_id"`
+	GuestName    string `json:"guest_name"`
+	Group        string `json:"group"`
+	MemberID     *uint  `json:"member_id,omitempty"`
+	MemberName   string `json:"member_name,omitempty"`
+	Seats        int    `json:"seats"`
+}
+
+type SeatingChartTable struct {
+	ID           uint              `json:"id"`
+	Name         string            `json:"name"`
+	Capacity     int               `json:"capacity"`
+	Used         int               `json:"used"`
+	OverCapacity bool              `json:"over_capacity"` // Bisa terjadi jika RSVP berubah setelah penempatan
+	Occupants    []SeatingOccupant `json:"occupants"`
+}
+
+type SeatingChart struct {
+	EventID   uint                `json:"event_id"`
+	EventName string              `json:"event_name"`
+	Tables    []SeatingChartTable `json:"tables"`
+}
+
+// GetSeatingChart mengembalikan denah tempat duduk per acara
+func GetSeatingChart(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	eventQuery := db.DB.Where("wedding_id = ?", weddingID)
+	if eventID := c.Query("event_id"); eventID != "" {
+		eventQuery = eventQuery.Where("id = ?", eventID)
+	}
+	var events []models.Event
+	if err := eventQuery.Order("start_at ASC").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	var tables []models.SeatingTable
+	if err := db.DB.Preload("Assignments").Where("wedding_id = ?", weddingID).Order("id ASC").Find(&tables).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tables"})
+		return
+	}
+
+	var allAssignments []models.SeatAssignment
+	for _, t := range tables {
+		allAssignments = append(allAssignments, t.Assignments...)
+	}
+	guests, err := loadGuestsByID(db.DB, allAssignments)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guests"})
+		return
+	}
+
+	tablesByEvent := map[uint][]models.SeatingTable{}
+	for _, t := range tables {
+		tablesByEvent[t.EventID] = append(tablesByEvent[t.EventID], t)
+	}
+
+	charts := make([]SeatingChart, 0, len(events))
+	for _, e := range events {
+		chart := SeatingChart{EventID: e.ID, EventName: e.Name, Tables: []SeatingChartTable{}}
+		for _, t := range tablesByEvent[e.ID] {
+			row := SeatingChartTable{ID: t.ID, Name: t.Name, Capacity: t.Capacity, Occupants: []SeatingOccupant{}}
+			for _, a := range t.Assignments {
+				g := guests[a.GuestID]
+				occupant := SeatingOccupant{
+					AssignmentID: a.ID,
+					GuestID:      g.ID,
+					GuestName:    g.Name,
+					Group:        g.Group,
+					MemberID:     a.GuestMemberID,
+					Seats:        assignmentSeats(a, guests),
+				}
+				if a.GuestMemberID != nil {
+					for _, m := range g.Members {
+						if m.ID == *a.GuestMemberID {
+							occupant.MemberName = m.Name
+						}
+					}
+				}
+				row.Used += occupant.Seats
+				row.Occupants = append(row.Occupants, occupant)
+			}
+			row.OverCapacity = row.Used > row.Capacity
+			chart.Tables = append(chart.Tables, row)
+		}
+		charts = append(charts, chart)
+	}
+
+	c.JSON(http.StatusOK, charts)
+}
+
+// GuestSeat adalah info meja untuk ditampilkan di undangan ("Meja Anda: 12")
+type GuestSeat struct {
+	EventID    uint   `json:"event_id"`
+	EventName  string `json:"event_name"`
+	TableID    uint   `json:"table_id"`
+	TableName  string `json:"table_name"`
+	MemberName string `json:"member_name,omitempty"` // Terisi jika hanya anggota tertentu yang duduk di meja ini
+}
+
+// getGuestSeats mengambil meja tamu di setiap acara
+func getGuestSeats(guestID uint) ([]GuestSeat, error) {
+	seats := []GuestSeat{}
+	err := db.DB.Table("seat_assignments").
+		Select("events.id as event_id, events.name as event_name, seating_tables.id as table_id, seating_tables.name as table_name, COALESCE(guest_members.name, '') as member_name").
+		Joins("JOIN seating_tables ON seating_tables.id = seat_assignments.table_id").
+		Joins("JOIN events ON events.id = seat_assignments.event_id").
+		Joins("LEFT JOIN guest_members ON guest_members.id = seat_assignments.guest_member_id").
+		Where("seat_assignments.guest_id = ?", guestID).
+		Order("events.start_at ASC, seating_tables.name ASC").
+		Scan(&seats).Error
+	return seats, err
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	"weddingpress_backend/internal/db"
+	"weddingpress_backend/internal/models"
 	"weddingpress_backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -11,27 +14,109 @@ import (
 
 // HandleUpload menangani upload file tunggal
 func HandleUpload(c *gin.Context) {
+	weddingID, err := getWeddingIDFromAuth(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wedding not found"})
+		return
+	}
+
+	// Batasi ukuran body agar file raksasa ditolak sebelum selesai dibaca
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxUploadBytes()+1<<20)
+
 	// Ambil file dari form-data dengan nama "file"
 	file, err := c.FormFile("file")
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded. Make sure the field name is 'file'"})
 		return
 	}
+	// Tujuan upload menentukan tipe & ukuran yang diizinkan (cover, music, gallery, qris, general)
+	purpose := c.PostForm("purpose")
+
+	usage, err := weddingStorageUsage(weddingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check storage quota"})
+		return
+	}
+	if err := usage.CheckQuota(file.Size); err != nil {
+		writeUploadError(c, err)
+		return
+	}
 
 	// (Opsional) Cek userID dari context untuk logging
 	userID, _ := c.Get("userID")
 	log.Printf("User %d is uploading a file...", userID)
 
-	// Panggil service upload
-	url, err := services.UploadToCloudinary(file)
+	// Panggil service upload (setiap wedding punya folder sendiri)
+	object, err := services.UploadFile(file, weddingMediaFolder(weddingID), purpose)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeUploadError(c, err)
 		return
 	}
+	if err := recordMediaAssets(db.DB, weddingID, object); err != nil {
+		discardUploads(object) // Termasuk jika kuota sudah terpakai upload lain yang berjalan bersamaan
+		writeUploadError(c, err)
+		return
+	}
+
+	// Kembalikan URL file yang sudah di-upload (beserta varian untuk gambar)
+	c.JSON(http.StatusOK, uploadResponse(object))
+}
 
-	// Kembalikan URL file yang sudah di-upload
-	c.JSON(http.StatusOK, gin.H{
-		"message": "File uploaded successfully",
-		"url":     url,
-	})
+// uploadResponse membuat respons upload: URL file beserta varian untuk gambar
+func uploadResponse(object services.UploadedFile) gin.H {
+	response := gin.H{
+		"message":      "File uploaded successfully",
+		"url":          object.URL,
+		"content_type": object.ContentType,
+		"size":         object.Size,
+	}
+	if object.Thumb != nil && object.Medium != nil {
+		response["thumb_url"] = object.Thumb.URL
+		response["medium_url"] = object.Medium.URL
+		response["width"] = object.Width
+		response["height"] = object.Height
+	}
+	return response
+}
+
+// galleryItemFromUpload mengisi data file (URL, tipe, varian) item galeri dari hasil upload
+func galleryItemFromUpload(upload services.UploadedFile) models.Gallery {
+	item := models.Gallery{
+		FileURL:  upload.URL,
+		FileType: upload.Category,
+		Width:    upload.Width,
+		Height:   upload.Height,
+	}
+	if upload.Thumb != nil {
+		item.ThumbURL = upload.Thumb.URL
+	}
+	if upload.Medium != nil {
+		item.MediumURL = upload.Medium.URL
+	}
+	return item
+}
+
+// discardUploads menghapus file yang sudah terunggah tapi gagal dicatat/dipakai
+func discardUploads(uploads ...services.UploadedFile) {
+	for _, upload := range uploads {
+		if err := deleteMediaAssetFiles(mediaAssetFromUpload(0, upload)); err != nil {
+			log.Printf("Failed to clean up %s: %v", upload.Key, err)
+		}
+	}
+}
+
+// writeUploadError mengirim kesalahan validasi upload (4xx) atau kesalahan storage (500)
+func writeUploadError(c *gin.Context, err error) {
+	var uploadErr *services.UploadError
+	if errors.As(err, &uploadErr) {
+		c.JSON(uploadErr.Status, gin.H{"error": uploadErr.Message})
+		return
+	}
+	log.Printf("Upload failed: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file"})
 }
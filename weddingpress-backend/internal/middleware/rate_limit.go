@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRateLimitKeys membatasi jumlah key yang dilacak satu RateLimit
+const maxRateLimitKeys = 10000
+
+// rateWindow adalah hitungan request satu key di jendela waktu yang sedang berjalan
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// RateLimit membatasi maksimal limit request per window untuk setiap key
+// (misal IP + slug tamu). Hitungan disimpan di memori proses, jadi batasnya
+// berlaku per instance server. Jika sudah ada maxRateLimitKeys key aktif, key baru ditolak.
+func RateLimit(limit int, window time.Duration, key func(*gin.Context) string) gin.HandlerFunc {
+	var mu sync.Mutex
+	windows := make(map[string]*rateWindow)
+	nextSweep := time.Now().Add(window)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		k := key(c)
+
+		mu.Lock()
+		// Buang jendela yang sudah lewat agar map tidak terus membesar
+		_, known := windows[k]
+		if now.After(nextSweep) || (!known && len(windows) >= maxRateLimitKeys) {
+			for staleKey, stale := range windows {
+				if now.After(stale.resetAt) {
+					delete(windows, staleKey)
+				}
+			}
+			nextSweep = now.Add(window)
+		}
+
+		allowed, retryAfter := false, window
+		w, ok := windows[k]
+		if ok || len(windows) < maxRateLimitKeys {
+			if !ok || now.After(w.resetAt) {
+				w = &rateWindow{resetAt: now.Add(window)}
+				windows[k] = w
+			}
+			w.count++
+			allowed = w.count <= limit
+			retryAfter = w.resetAt.Sub(now)
+		}
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Terlalu banyak permintaan, silakan coba lagi nanti"})
+			return
+		}
+		c.Next()
+	}
+}
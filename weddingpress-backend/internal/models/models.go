@@ -26,10 +26,19 @@ type Wedding struct {
 	MusicURL      string `gorm:"size:512" json:"music_url"`
 	ThemeColor    string `gorm:"size:50" json:"theme_color"`
 
+	// Musik latar: lagu dari pustaka musik (opsional) & preferensi pemutar
+	MusicTrackID     *uint   `json:"music_track_id"`                      // Jika diisi, MusicURL = URL lagu ini
+	MusicStartOffset float64 `gorm:"default:0" json:"music_start_offset"` // Detik dari awal lagu
+	MusicLoop        bool    `gorm:"default:true" json:"music_loop"`
+	MusicVolume      float64 `gorm:"default:0.8" json:"music_volume"` // 0.0 - 1.0
+
 	// --- TAMBAHAN FITUR TEMPLATE ---
 	Template string `gorm:"size:50;default:'modern'" json:"template"`
 	// ------------------------------
 
+	// Zona waktu IANA default untuk acara (misal: "Asia/Jakarta", "Asia/Makassar")
+	Timezone string `gorm:"size:64;default:'Asia/Jakarta'" json:"timezone"`
+
 	// --- TAMBAHKAN FIELD KUSTOMISASI DI SINI ---
 	// 'default:true' berarti semua bagian akan tampil secara default
 	ShowEvents    bool `gorm:"default:true" json:"show_events"`
@@ -39,13 +48,33 @@ type Wedding struct {
 	ShowGuestBook bool `gorm:"default:true" json:"show_guest_book"`
 	// ------------------------------------------
 
+	// Paket langganan ("free", "premium", "business"); menentukan kuota storage.
+	// Diatur oleh pengelola layanan, tidak bisa diubah lewat UpdateMyWedding.
+	// Tidak ikut di JSON undangan publik; admin membacanya lewat GetStorageUsage.
+	Plan string `gorm:"size:20;default:'free'" json:"-"`
+
+	// Moderasi buku tamu: ucapan tanpa kata terlarang bisa langsung tampil (auto-approve).
+	// Blocklist berisi kata terlarang tambahan (dipisah koma) selain daftar bawaan.
+	// Disembunyikan dari JSON; admin membacanya lewat GetGuestBookSettings.
+	GuestBookAutoApprove  bool   `gorm:"default:false" json:"-"`
+	GuestBookBlocklist    string `gorm:"type:text" json:"-"`
+	GuestBookMessageLimit int    `gorm:"default:3" json:"-"` // Maks. ucapan per tamu (0 = tanpa batas)
+
+	// Tamu boleh mengirim foto lewat link undangan (masuk antrean moderasi)
+	GuestUploadsEnabled bool `gorm:"default:false" json:"guest_uploads_enabled"`
+
+	// Token rahasia untuk feed kalender (.ics) yang bisa di-subscribe admin
+	CalendarToken string `gorm:"size:64;index" json:"-"`
+
 	// Relasi
-	GroomBride   GroomBride    `gorm:"foreignKey:WeddingID" json:"groom_bride"`   // Has One
-	Events       []Event       `gorm:"foreignKey:WeddingID" json:"events"`        // Has Many
-	Stories      []Story       `gorm:"foreignKey:WeddingID" json:"stories"`       // Has Many
-	Galleries    []Gallery     `gorm:"foreignKey:WeddingID" json:"galleries"`     // Has Many
-	Guests       []Guest       `gorm:"foreignKey:WeddingID" json:"guests"`        // Has Many
-	GiftAccounts []GiftAccount `gorm:"foreignKey:WeddingID" json:"gift_accounts"` // <-- TAMBAHKAN RELASI INI
+	GroomBride    GroomBride     `gorm:"foreignKey:WeddingID" json:"groom_bride"`    // Has One
+	Events        []Event        `gorm:"foreignKey:WeddingID" json:"events"`         // Has Many
+	Stories       []Story        `gorm:"foreignKey:WeddingID" json:"stories"`        // Has Many
+	Galleries     []Gallery      `gorm:"foreignKey:WeddingID" json:"galleries"`      // Has Many
+	GalleryAlbums []GalleryAlbum `gorm:"foreignKey:WeddingID" json:"gallery_albums"` // Has Many
+	Guests        []Guest        `gorm:"foreignKey:WeddingID" json:"guests"`         // Has Many
+	GiftAccounts  []GiftAccount  `gorm:"foreignKey:WeddingID" json:"gift_accounts"`  // <-- TAMBAHKAN RELASI INI
+	RSVPQuestions []RSVPQuestion `gorm:"foreignKey:WeddingID" json:"rsvp_questions"` // Has Many (pertanyaan RSVP kustom)
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -63,26 +92,74 @@ type GroomBride struct {
 	BrideBio      string `gorm:"type:text" json:"bride_bio"`
 }
 
+// EventDirections berisi link petunjuk arah siap pakai ke lokasi acara
+type EventDirections struct {
+	GoogleMaps string `json:"google_maps"`
+	Waze       string `json:"waze,omitempty"`
+	AppleMaps  string `json:"apple_maps,omitempty"`
+}
+
 // Event untuk acara (misal: Akad, Resepsi)
 type Event struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	WeddingID uint      `gorm:"not null" json:"wedding_id"`
-	Name      string    `gorm:"size:255;not null" json:"name"`
+	ID        uint       `gorm:"primarykey" json:"id"`
+	WeddingID uint       `gorm:"not null" json:"wedding_id"`
+	Name      string     `gorm:"size:255;not null" json:"name"`
+	StartAt   time.Time  `gorm:"type:timestamptz;index" json:"start_at"` // Waktu mulai (absolut)
+	EndAt     *time.Time `gorm:"type:timestamptz" json:"end_at"`         // Opsional, harus setelah StartAt
+	Timezone  string     `gorm:"size:64" json:"timezone"`                // Zona waktu IANA lokasi acara
+
+	// Kolom lama, diturunkan dari StartAt/EndAt di zona waktu acara (untuk kompatibilitas)
 	Date      time.Time `gorm:"type:date" json:"date"`
 	StartTime string    `gorm:"size:10" json:"start_time"` // Format "HH:MM"
 	EndTime   string    `gorm:"size:10" json:"end_time"`
-	Address   string    `gorm:"type:text" json:"address"`
-	MapsURL   string    `gorm:"size:512" json:"maps_url"`
+
+	Address string `gorm:"type:text" json:"address"`
+	MapsURL string `gorm:"size:512" json:"maps_url"`
+
+	// Lokasi acara
+	VenueName    string           `gorm:"size:255" json:"venue_name"`
+	Latitude     *float64         `json:"latitude"`
+	Longitude    *float64         `json:"longitude"`
+	ParkingNotes string           `gorm:"type:text" json:"parking_notes"`
+	Directions   *EventDirections `gorm:"-" json:"directions,omitempty"` // Diisi di undangan publik
+
+	// Live streaming (opsional). Di undangan publik, URL & catatan akses hanya dikirim ke
+	// tamu yang RSVP virtual, mulai LiveStreamRevealMinutes sebelum streaming dimulai.
+	LiveStreamURL           string     `gorm:"size:512" json:"live_stream_url"`
+	LiveStreamPlatform      string     `gorm:"size:30" json:"live_stream_platform"` // youtube, zoom, instagram, dll
+	LiveStreamStartAt       *time.Time `gorm:"type:timestamptz" json:"live_stream_start_at"`
+	LiveStreamNote          string     `gorm:"type:text" json:"live_stream_note"`        // Misal: passcode Zoom
+	LiveStreamRevealMinutes int        `json:"live_stream_reveal_minutes"`               // 0 = saat streaming dimulai (default 60 diisi handler)
+	LiveStreamRevealAt      *time.Time `gorm:"-" json:"live_stream_reveal_at,omitempty"` // Diisi di undangan publik
+
+	// Siapa yang diundang ke acara ini: semua tamu, grup tertentu, atau tamu tertentu
+	Audience       string       `gorm:"size:20;default:'all'" json:"audience"`
+	AudienceGroups []GuestGroup `gorm:"many2many:event_audience_groups" json:"audience_groups,omitempty"`
+	AudienceGuests []Guest      `gorm:"many2many:event_audience_guests" json:"audience_guests,omitempty"`
 }
 
+// Nilai Event.Audience
+const (
+	EventAudienceAll    = "all"
+	EventAudienceGroups = "groups"
+	EventAudienceGuests = "guests"
+)
+
 // Story adalah timeline cerita
 type Story struct {
 	ID          uint      `gorm:"primarykey" json:"id"`
 	WeddingID   uint      `gorm:"not null" json:"wedding_id"`
 	Title       string    `gorm:"size:255;not null" json:"title"`
 	Date        time.Time `gorm:"type:date" json:"date"`
-	Description string    `gorm:"type:text" json:"description"`
+	Description string    `gorm:"type:text" json:"description"` // Markdown mentah (link sudah divalidasi)
 	Order       int       `gorm:"default:0" json:"order"`
+
+	// Media opsional (hasil upload wedding ini) & label lokasi
+	MediaURL  string `gorm:"size:512" json:"media_url"`
+	MediaType string `gorm:"size:20" json:"media_type"` // "image" atau "video"
+	Location  string `gorm:"size:255" json:"location"`
+
+	DescriptionHTML string `gorm:"-" json:"description_html,omitempty"` // Description yang sudah dirender (undangan publik)
 }
 
 // Gallery untuk foto/video
@@ -92,6 +169,125 @@ type Gallery struct {
 	FileURL   string `gorm:"size:512;not null" json:"file_url"`
 	FileType  string `gorm:"size:50;not null" json:"file_type"` // "image" atau "video"
 	Caption   string `gorm:"size:255" json:"caption"`
+	ThumbURL  string `gorm:"size:512" json:"thumb_url"`  // Varian kecil (sisi terpanjang 400px)
+	MediumURL string `gorm:"size:512" json:"medium_url"` // Varian sedang (1280px); FileURL = varian penuh
+	Width     int    `json:"width"`                      // Ukuran FileURL (0 jika tidak diketahui)
+	Height    int    `json:"height"`
+	AlbumID   *uint  `gorm:"index" json:"album_id"`         // Kosong = tanpa album
+	Order     int    `gorm:"default:0" json:"order"`        // Urutan di dalam album
+	IsCover   bool   `gorm:"default:false" json:"is_cover"` // Sampul album (maks. satu per album)
+}
+
+// GalleryAlbum mengelompokkan galeri (misal: "Prewedding", "Lamaran", "Keluarga")
+type GalleryAlbum struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	WeddingID   uint      `gorm:"not null;index" json:"wedding_id"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Order       int       `gorm:"default:0" json:"order"`
+	Items       []Gallery `gorm:"foreignKey:AlbumID" json:"items"`
+	CoverURL    string    `gorm:"-" json:"cover_url"` // Item bertanda sampul, atau item pertama
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MediaAsset mencatat setiap file yang di-upload sebuah wedding (beserta varian gambarnya)
+// agar file yang tidak lagi dipakai bisa dibersihkan dari storage
+type MediaAsset struct {
+	ID          uint   `gorm:"primarykey" json:"id"`
+	WeddingID   uint   `gorm:"not null;index" json:"wedding_id"`
+	Provider    string `gorm:"size:20;not null" json:"provider"`         // Driver storage: "cloudinary", "local", "s3"
+	Key         string `gorm:"size:512;not null" json:"key"`             // Public ID / key di storage
+	URL         string `gorm:"size:512;not null;uniqueIndex" json:"url"` // URL yang dipakai di record lain
+	ContentType string `gorm:"size:100" json:"content_type"`
+	Category    string `gorm:"size:20" json:"category"` // "image", "video" atau "audio"
+	Size        int64  `json:"size"`                    // Total byte tersimpan, termasuk varian
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+
+	// Varian gambar (kosong untuk file non-gambar)
+	ThumbKey  string `gorm:"size:512" json:"-"`
+	ThumbURL  string `gorm:"size:512;index" json:"thumb_url"`
+	MediumKey string `gorm:"size:512" json:"-"`
+	MediumURL string `gorm:"size:512;index" json:"medium_url"`
+
+	References []MediaReference `gorm:"-" json:"references"` // Diisi saat dilaporkan
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Status foto kiriman tamu
+const (
+	GuestPhotoPending  = "pending"  // Menunggu moderasi admin
+	GuestPhotoApproved = "approved" // Sudah dipindah ke galeri
+)
+
+// GuestPhoto adalah foto yang dikirim tamu lewat link undangan.
+// Setelah di-approve, foto menjadi item Gallery (GalleryID).
+type GuestPhoto struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	WeddingID uint   `gorm:"not null;index" json:"wedding_id"`
+	GuestID   uint   `gorm:"not null;index" json:"guest_id"`
+	Guest     *Guest `gorm:"foreignKey:GuestID" json:"guest,omitempty"`
+	FileURL   string `gorm:"size:512;not null" json:"file_url"`
+	ThumbURL  string `gorm:"size:512" json:"thumb_url"`
+	MediumURL string `gorm:"size:512" json:"medium_url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Caption   string `gorm:"size:255" json:"caption"`
+	Status    string `gorm:"size:20;default:'pending';index" json:"status"`
+	GalleryID *uint  `json:"gallery_id"` // Item galeri hasil approve
+
+	ModeratedAt *time.Time `json:"moderated_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// MusicTrack adalah lagu di pustaka musik latar milik wedding
+type MusicTrack struct {
+	ID          uint    `gorm:"primarykey" json:"id"`
+	WeddingID   uint    `gorm:"not null;index" json:"wedding_id"`
+	URL         string  `gorm:"size:512;not null" json:"url"`
+	Title       string  `gorm:"size:255" json:"title"`
+	Artist      string  `gorm:"size:255" json:"artist"`
+	Album       string  `gorm:"size:255" json:"album"`
+	Duration    float64 `json:"duration"` // Detik (0 jika tidak diketahui)
+	ContentType string  `gorm:"size:50" json:"content_type"`
+	Size        int64   `json:"size"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Status sesi upload bertahap
+const (
+	UploadStatusUploading  = "uploading"  // Menerima potongan
+	UploadStatusAssembling = "assembling" // Sedang digabung & dikirim ke storage
+)
+
+// UploadSession adalah sesi upload bertahap (chunked) untuk file besar seperti video.
+// Potongan disimpan sementara di disk server sampai sesi selesai atau dibatalkan.
+type UploadSession struct {
+	ID          string    `gorm:"primarykey;size:32" json:"id"`
+	WeddingID   uint      `gorm:"not null;index" json:"wedding_id"`
+	Filename    string    `gorm:"size:255" json:"filename"`
+	Purpose     string    `gorm:"size:20;not null" json:"purpose"`
+	TotalSize   int64     `gorm:"not null" json:"total_size"`
+	ChunkSize   int64     `gorm:"not null" json:"chunk_size"`
+	TotalChunks int       `gorm:"not null" json:"total_chunks"`
+	SHA256      string    `gorm:"size:64" json:"sha256"` // Checksum seluruh file (hex), boleh dikirim saat complete
+	Status      string    `gorm:"size:20;default:'uploading'" json:"status"`
+	ExpiresAt   time.Time `gorm:"index" json:"expires_at"`
+
+	ReceivedChunks []int `gorm:"-" json:"received_chunks"` // Indeks potongan yang sudah diterima
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MediaReference adalah record yang memakai URL sebuah MediaAsset
+type MediaReference struct {
+	Type  string `json:"type"` // Misal "gallery", "story", "gift_account"
+	ID    uint   `json:"id"`
+	Field string `json:"field"` // Misal "file_url", "qr_code_url"
 }
 
 // Guest adalah tamu undangan
@@ -100,23 +296,159 @@ type Guest struct {
 	WeddingID       uint   `gorm:"not null" json:"wedding_id"`
 	Name            string `gorm:"size:255;not null" json:"name"`
 	Slug            string `gorm:"size:100;not null;uniqueIndex" json:"slug"` // Pakai uniqueIndex
-	Group           string `gorm:"size:100" json:"group"`
+	GroupID         *uint  `gorm:"index" json:"group_id"`
+	Group           string `gorm:"size:100" json:"group"` // Salinan nama GuestGroup (disinkronkan saat rename/merge)
 	IsRSVP          bool   `gorm:"default:false" json:"is_rsvp"`
 	TotalAttendance int    `gorm:"default:0" json:"total_attendance"`
+	AttendanceQuota int    `gorm:"default:2" json:"attendance_quota"` // Maks. orang yang boleh hadir
+	AttendanceMode  string `gorm:"size:20" json:"attendance_mode"`    // in_person, virtual, declined (kosong = belum RSVP)
+
+	GuestBooks []GuestBook   `gorm:"foreignKey:GuestID" json:"guest_books"`           // Has Many (ucapan tamu)
+	Members    []GuestMember `gorm:"foreignKey:GuestID" json:"members"`               // Has Many (anggota rombongan)
+	Answers    []RSVPAnswer  `gorm:"foreignKey:GuestID" json:"rsvp_answers"`          // Has Many (jawaban RSVP kustom)
+	GuestGroup *GuestGroup   `gorm:"foreignKey:GroupID" json:"guest_group,omitempty"` // Belongs To
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Pilihan kehadiran saat RSVP
+const (
+	AttendanceInPerson = "in_person"
+	AttendanceVirtual  = "virtual" // Menonton live streaming
+	AttendanceDeclined = "declined"
+)
 
-	GuestBook GuestBook `gorm:"foreignKey:GuestID" json:"guest_book"` // Has One
+// GuestGroup adalah grup tamu (misal: "Keluarga", "Teman Kantor") beserta pengaturannya
+type GuestGroup struct {
+	ID           uint   `gorm:"primarykey" json:"id"`
+	WeddingID    uint   `gorm:"not null;index" json:"wedding_id"`
+	Name         string `gorm:"size:100;not null" json:"name"`  // Unik per wedding tanpa peka huruf besar/kecil: lihat db.RunMigrations
+	DefaultQuota int    `gorm:"default:2" json:"default_quota"` // Kuota awal untuk tamu baru di grup ini
+	Greeting     string `gorm:"type:text" json:"greeting"`      // Sapaan kustom di undangan, boleh pakai {name}
+
+	// Acara yang terlihat oleh grup ini. Kosong berarti semua acara terlihat.
+	VisibleEvents []Event `gorm:"many2many:guest_group_events" json:"visible_events"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Kategori usia anggota rombongan
+const (
+	AgeCategoryAdult = "adult"
+	AgeCategoryChild = "child"
+)
+
+// GuestMember adalah anggota rombongan (keluarga / plus-one) dari satu Guest
+type GuestMember struct {
+	ID          uint   `gorm:"primarykey" json:"id"`
+	GuestID     uint   `gorm:"not null;index" json:"guest_id"`
+	Name        string `gorm:"size:255;not null" json:"name"`
+	AgeCategory string `gorm:"size:20;default:'adult'" json:"age_category"` // "adult" atau "child"
+	Diet        string `gorm:"size:50" json:"diet"`                         // Misal: "vegetarian", "halal" (kosong = tidak ada)
+	Allergies   string `gorm:"size:255" json:"allergies"`                   // Catatan alergi (bebas)
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Tipe pertanyaan RSVP kustom
+const (
+	QuestionTypeText         = "text"
+	QuestionTypeSingleChoice = "single_choice"
+	QuestionTypeMultiChoice  = "multi_choice"
+	QuestionTypeNumber       = "number"
+)
+
+// RSVPQuestion adalah pertanyaan tambahan di form RSVP (misal: "Butuh transportasi?")
+type RSVPQuestion struct {
+	ID        uint     `gorm:"primarykey" json:"id"`
+	WeddingID uint     `gorm:"not null;index" json:"wedding_id"`
+	Question  string   `gorm:"size:255;not null" json:"question"`
+	Type      string   `gorm:"size:20;not null" json:"type"`             // text, single_choice, multi_choice, number
+	Options   []string `gorm:"type:text;serializer:json" json:"options"` // Hanya untuk tipe pilihan
+	Required  bool     `gorm:"default:false" json:"required"`
+	Order     int      `gorm:"default:0" json:"order"`
+}
+
+// RSVPAnswer adalah jawaban tamu untuk satu RSVPQuestion
+type RSVPAnswer struct {
+	ID         uint     `gorm:"primarykey" json:"id"`
+	GuestID    uint     `gorm:"not null;uniqueIndex:idx_rsvp_answer_guest_question" json:"guest_id"`
+	QuestionID uint     `gorm:"not null;uniqueIndex:idx_rsvp_answer_guest_question" json:"question_id"`
+	Value      string   `gorm:"type:text" json:"value"`                   // Untuk text & number
+	Choices    []string `gorm:"type:text;serializer:json" json:"choices"` // Untuk single/multi choice
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SeatingTable adalah meja pada denah tempat duduk untuk satu acara
+type SeatingTable struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	WeddingID uint   `gorm:"not null;index" json:"wedding_id"`
+	EventID   uint   `gorm:"not null;index" json:"event_id"`
+	Name      string `gorm:"size:100;not null" json:"name"` // Misal: "12" atau "Keluarga Inti"
+	Capacity  int    `gorm:"not null" json:"capacity"`
+
+	Assignments []SeatAssignment `gorm:"foreignKey:TableID" json:"assignments"` // Has Many
+}
+
+// SeatAssignment menempatkan satu tamu (seluruh rombongan) atau satu anggota rombongan di meja.
+// GuestMemberID kosong berarti seluruh rombongan tamu duduk di meja ini.
+type SeatAssignment struct {
+	ID            uint  `gorm:"primarykey" json:"id"`
+	TableID       uint  `gorm:"not null;index" json:"table_id"`
+	EventID       uint  `gorm:"not null;index" json:"event_id"` // Disalin dari meja, untuk cek duplikasi per acara
+	GuestID       uint  `gorm:"not null;index" json:"guest_id"`
+	GuestMemberID *uint `gorm:"index" json:"guest_member_id"` // Unik per (event, tamu, anggota): lihat db.RunMigrations
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Status ucapan buku tamu
+const (
+	GuestBookPending  = "pending"  // Menunggu moderasi
+	GuestBookApproved = "approved" // Tampil di undangan
+	GuestBookRejected = "rejected" // Ditolak admin
+	GuestBookHidden   = "hidden"   // Pernah tampil, lalu disembunyikan admin
+)
+
 // GuestBook untuk ucapan
 type GuestBook struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	GuestID   uint      `gorm:"not null;uniqueIndex" json:"guest_id"` // Pastikan unik
-	Message   string    `gorm:"type:text;not null" json:"message"`
-	Status    string    `gorm:"size:50;default:'pending'" json:"status"` // "pending" atau "approved"
-	CreatedAt time.Time `json:"created_at"`
+	ID           uint       `gorm:"primarykey" json:"id"`
+	GuestID      uint       `gorm:"not null;index:idx_guest_books_guest" json:"guest_id"` // Satu tamu bisa beberapa ucapan
+	Message      string     `gorm:"type:text;not null" json:"message"`
+	Status       string     `gorm:"size:50;default:'pending'" json:"status"` // Lihat konstanta GuestBook*
+	Flagged      bool       `gorm:"default:false;index" json:"flagged"`      // Mengandung kata terlarang
+	FlaggedWords string     `gorm:"size:255" json:"flagged_words"`           // Kata yang ditemukan (dipisah koma)
+	ModeratedAt  *time.Time `json:"moderated_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Replies []GuestBookReply `gorm:"foreignKey:GuestBookID" json:"replies,omitempty"` // Has Many (balasan mempelai)
+}
+
+// GuestBookReply adalah balasan mempelai untuk satu ucapan
+type GuestBookReply struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	GuestBookID uint      `gorm:"not null;index" json:"guest_book_id"`
+	UserID      *uint     `json:"user_id"` // Admin yang membalas
+	Message     string    `gorm:"type:text;not null" json:"message"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GuestBookModerationLog mencatat siapa mengubah status ucapan dan kapan
+type GuestBookModerationLog struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	WeddingID   uint      `gorm:"not null;index" json:"wedding_id"`
+	GuestBookID uint      `gorm:"not null;index" json:"guest_book_id"`
+	UserID      *uint     `json:"user_id"`                        // Kosong = otomatis oleh sistem
+	Action      string    `gorm:"size:30;not null" json:"action"` // "submitted", "status_changed", "deleted"
+	FromStatus  string    `gorm:"size:20" json:"from_status"`
+	ToStatus    string    `gorm:"size:20" json:"to_status"`
+	Note        string    `gorm:"type:text" json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // GiftAccount untuk rekening bank atau e-wallet
@@ -127,4 +459,5 @@ type GiftAccount struct {
 	AccountNumber string `gorm:"size:100;not null" json:"account_number"` // No. Rekening / No. HP
 	AccountName   string `gorm:"size:255;not null" json:"account_name"`   // Atas Nama
 	QRCodeURL     string `gorm:"size:512" json:"qr_code_url"`             // URL ke gambar QRIS (Opsional)
+	Order         int    `gorm:"default:0" json:"order"`
 }
@@ -2,15 +2,22 @@ package routes
 
 import (
 	"net/http"
+	"time"
 
 	"weddingpress_backend/internal/handlers"
 	"weddingpress_backend/internal/middleware"
+	"weddingpress_backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRoutes(r *gin.Engine) {
 
+	// File upload untuk storage lokal (STORAGE_DRIVER=local)
+	if local, ok := storage.Active.(*storage.LocalStorage); ok {
+		r.Static(local.URLPath, local.Dir)
+	}
+
 	// Grup rute /api/v1
 	api := r.Group("/api/v1")
 	{
@@ -31,23 +38,62 @@ func SetupRoutes(r *gin.Engine) {
 			// Upload
 			admin.POST("/upload", handlers.HandleUpload)
 
+			// Upload bertahap (chunked) untuk file besar
+			admin.POST("/uploads", handlers.InitiateChunkedUpload)
+			admin.GET("/uploads/:id", handlers.GetChunkedUpload)
+			admin.PUT("/uploads/:id/parts/:index", handlers.UploadChunk)
+			admin.POST("/uploads/:id/complete", handlers.CompleteChunkedUpload)
+			admin.DELETE("/uploads/:id", handlers.AbortChunkedUpload)
+
+			// Media asset (hasil upload) & pembersihan file yang tidak dipakai
+			admin.GET("/media", handlers.GetMediaAssets)
+			admin.GET("/media/orphans", handlers.GetMediaOrphans)
+			admin.DELETE("/media/orphans", handlers.PurgeMediaOrphans)
+			admin.DELETE("/media/:id", handlers.DeleteMediaAsset)
+			admin.GET("/storage/usage", handlers.GetStorageUsage)
+
 			// Wedding (Data Utama & GroomBride)
 			admin.GET("/wedding", handlers.GetMyWedding)
 			admin.PUT("/wedding", handlers.UpdateMyWedding)
 
+			// Musik latar (pustaka lagu & preferensi pemutar)
+			admin.GET("/music-tracks", handlers.GetMusicTracks)
+			admin.POST("/music-track", handlers.UploadMusicTrack)
+			admin.PUT("/music-track/:id", handlers.UpdateMusicTrack)
+			admin.DELETE("/music-track/:id", handlers.DeleteMusicTrack)
+			admin.GET("/music-settings", handlers.GetMusicSettings)
+			admin.PUT("/music-settings", handlers.UpdateMusicSettings)
+
 			// Gallery
 			admin.GET("/gallery", handlers.GetGallery)
 			admin.POST("/gallery", handlers.CreateGalleryItem)
+			admin.POST("/gallery/bulk", handlers.BulkUploadGallery)
+			admin.PUT("/gallery/reorder", handlers.ReorderGallery)
+			admin.DELETE("/gallery/bulk", handlers.BulkDeleteGallery)
+			admin.PUT("/gallery/:id", handlers.UpdateGalleryItem)
+			admin.PUT("/gallery/:id/cover", handlers.SetGalleryCover)
 			admin.DELETE("/gallery/:id", handlers.DeleteGalleryItem)
 
+			// Gallery Albums
+			admin.GET("/gallery-albums", handlers.GetGalleryAlbums)
+			admin.PUT("/gallery-albums/reorder", handlers.ReorderGalleryAlbums)
+			admin.POST("/gallery-album", handlers.CreateGalleryAlbum)
+			admin.PUT("/gallery-album/:id", handlers.UpdateGalleryAlbum)
+			admin.DELETE("/gallery-album/:id", handlers.DeleteGalleryAlbum)
+
 			// Event
 			admin.GET("/events", handlers.GetEvents)
 			admin.POST("/event", handlers.CreateEvent)
 			admin.PUT("/event/:id", handlers.UpdateEvent)
 			admin.DELETE("/event/:id", handlers.DeleteEvent)
 
+			// Feed kalender (.ics) yang bisa di-subscribe
+			admin.GET("/calendar-feed", handlers.GetCalendarFeedURL)
+			admin.POST("/calendar-feed/regenerate", handlers.RegenerateCalendarFeed)
+
 			// Story
 			admin.GET("/stories", handlers.GetStories)
+			admin.PUT("/stories/reorder", handlers.ReorderStories)
 			admin.POST("/story", handlers.CreateStory)
 			admin.PUT("/story/:id", handlers.UpdateStory)
 			admin.DELETE("/story/:id", handlers.DeleteStory)
@@ -64,15 +110,62 @@ func SetupRoutes(r *gin.Engine) {
 			// !!! TAMBAHKAN BARIS INI !!!
 			admin.DELETE("/guest/bulk", handlers.DeleteGuestsBulk)
 
+			// Grup tamu
+			admin.GET("/groups", handlers.GetGroups)
+			admin.POST("/group", handlers.CreateGroup)
+			admin.PUT("/group/:id", handlers.UpdateGroup)
+			admin.DELETE("/group/:id", handlers.DeleteGroup)
+			admin.POST("/group/:id/merge", handlers.MergeGroup)
+
+			// Anggota rombongan tamu (household / plus-one)
+			admin.GET("/guest/:id/members", handlers.GetGuestMembers)
+			admin.POST("/guest/:id/member", handlers.CreateGuestMember)
+			admin.PUT("/guest/:id/member/:member_id", handlers.UpdateGuestMember)
+			admin.DELETE("/guest/:id/member/:member_id", handlers.DeleteGuestMember)
+
+			// Laporan & ekspor tamu
+			admin.GET("/guests/report", handlers.GetGuestReport)
+			admin.GET("/guests/export", handlers.ExportGuests)
+
+			// Pertanyaan RSVP kustom
+			admin.GET("/rsvp-questions", handlers.GetRSVPQuestions)
+			admin.GET("/rsvp-questions/summary", handlers.GetRSVPSummary)
+			admin.POST("/rsvp-question", handlers.CreateRSVPQuestion)
+			admin.PUT("/rsvp-question/:id", handlers.UpdateRSVPQuestion)
+			admin.DELETE("/rsvp-question/:id", handlers.DeleteRSVPQuestion)
+
+			// Meja & denah tempat duduk
+			admin.GET("/tables", handlers.GetSeatingTables)
+			admin.GET("/tables/chart", handlers.GetSeatingChart)
+			admin.POST("/tables/auto-assign", handlers.AutoAssignSeats)
+			admin.POST("/table", handlers.CreateSeatingTable)
+			admin.PUT("/table/:id", handlers.UpdateSeatingTable)
+			admin.DELETE("/table/:id", handlers.DeleteSeatingTable)
+			admin.POST("/table/:id/assign", handlers.AssignSeat)
+			admin.DELETE("/seat-assignment/:id", handlers.UnassignSeat)
+
 			// GuestBook (Admin)
 			admin.GET("/guestbook", handlers.GetGuestBookAdmin)
+			admin.GET("/guestbook/settings", handlers.GetGuestBookSettings)
+			admin.PUT("/guestbook/settings", handlers.UpdateGuestBookSettings) // Auto-approve & kata terlarang
+			admin.GET("/guestbook/moderation-log", handlers.GetGuestBookModerationLog)
 			admin.PUT("/guestbook/:id", handlers.UpdateGuestBookStatus) // Approve/Reject
 			admin.DELETE("/guestbook/:id", handlers.DeleteGuestBook)
-			admin.DELETE("/guestbook/bulk", handlers.BulkDeleteGuestBook) // <-- TAMBAHKAN INI
+			admin.DELETE("/guestbook/bulk", handlers.BulkDeleteGuestBook)       // <-- TAMBAHKAN INI
+			admin.POST("/guestbook/:id/replies", handlers.CreateGuestBookReply) // Balasan mempelai
+			admin.PUT("/guestbook-reply/:id", handlers.UpdateGuestBookReply)
+			admin.DELETE("/guestbook-reply/:id", handlers.DeleteGuestBookReply)
 
 			// === TAMBAHKAN RUTE BARU DI SINI ===
+			// Foto kiriman tamu (moderasi)
+			admin.GET("/guest-photos", handlers.GetGuestPhotos)
+			admin.PUT("/guest-photos/settings", handlers.UpdateGuestPhotoSettings)
+			admin.POST("/guest-photo/:id/approve", handlers.ApproveGuestPhoto)
+			admin.DELETE("/guest-photo/:id", handlers.RejectGuestPhoto)
+
 			// Gift Accounts (Amplop Digital)
 			admin.GET("/gift-accounts", handlers.GetGiftAccounts)
+			admin.PUT("/gift-accounts/reorder", handlers.ReorderGiftAccounts)
 			admin.POST("/gift-account", handlers.CreateGiftAccount)
 			admin.PUT("/gift-account/:id", handlers.UpdateGiftAccount)
 			admin.DELETE("/gift-account/:id", handlers.DeleteGiftAccount)
@@ -80,8 +173,24 @@ func SetupRoutes(r *gin.Engine) {
 
 		// --- Rute Publik (Untuk Halaman Undangan) ---
 		api.GET("/invitation/slug/:guest_slug", handlers.GetInvitationBySlug)
+		api.GET("/invitation/slug/:guest_slug/calendar.ics", handlers.GetGuestCalendar)
+		api.GET("/calendar/:token/feed.ics", handlers.GetWeddingCalendarFeed)
 		api.POST("/rsvp/:guest_id", handlers.PostRSVP)
-		api.POST("/guestbook/:guest_id", handlers.PostGuestBook)
+		// Ucapan dibatasi per IP: guest_id berurutan, jadi batas per guest_id bisa dihabiskan orang lain
+		api.POST("/guestbook/:guest_id",
+			middleware.RateLimit(20, 10*time.Minute, func(c *gin.Context) string {
+				return c.ClientIP()
+			}),
+			handlers.PostGuestBook)
+
+		// Foto kiriman tamu (dibatasi per tamu; slug dicek dulu agar slug asal tidak mengisi rate limit)
+		api.GET("/invitation/slug/:guest_slug/photos", handlers.GetMyGuestPhotos)
+		api.POST("/invitation/slug/:guest_slug/photos",
+			handlers.ResolveGuestSlug,
+			middleware.RateLimit(10, 10*time.Minute, func(c *gin.Context) string {
+				return c.Param("guest_slug") // Sudah divalidasi ResolveGuestSlug
+			}),
+			handlers.UploadGuestPhoto)
 		api.GET("/guestbook/:wedding_id", handlers.GetGuestBook) // Versi publik (hanya yg approved)
 	}
 
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// AudioMetadata adalah info dasar file audio. Pembacaan bersifat "best effort":
+// field yang tidak ditemukan dibiarkan kosong (Duration 0 = tidak diketahui).
+type AudioMetadata struct {
+	Duration float64 // Detik
+	Title    string
+	Artist   string
+	Album    string
+}
+
+// ParseAudioMetadata membaca durasi & tag dari MP3 (ID3v1/ID3v2), WAV (RIFF INFO) dan FLAC
+// (Vorbis comment). Format lain (M4A, OGG, AAC) mengembalikan metadata kosong.
+func ParseAudioMetadata(data []byte, contentType string) AudioMetadata {
+	var meta AudioMetadata
+	switch contentType {
+	case "audio/mpeg":
+		meta = parseMP3(data)
+	case "audio/wav":
+		meta = parseWAV(data)
+	case "audio/flac":
+		meta = parseFLAC(data)
+	}
+	meta.Title = cleanTag(meta.Title)
+	meta.Artist = cleanTag(meta.Artist)
+	meta.Album = cleanTag(meta.Album)
+	return meta
+}
+
+// cleanTag merapikan nilai tag (null terminator, spasi, UTF-8 tidak valid, panjang)
+func cleanTag(s string) string {
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(strings.ToValidUTF8(s, ""))
+	for len(s) > 255 {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s
+}
+
+// --- MP3 ---
+
+var mp3Bitrates = map[[2]int][16]int{ // [versi MPEG1?, layer] -> kbps
+	{1, 1}: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+	{1, 2}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+	{1, 3}: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	{0, 1}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+	{0, 2}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	{0, 3}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+}
+
+var mp3SampleRates = map[int][3]int{ // bit versi -> Hz
+	3: {44100, 48000, 32000}, // MPEG1
+	2: {22050, 24000, 16000}, // MPEG2
+	0: {11025, 12000, 8000},  // MPEG2.5
+}
+
+// mp3Frame adalah header frame MPEG audio
+type mp3Frame struct {
+	version    int // Bit versi: 3 = MPEG1, 2 = MPEG2, 0 = MPEG2.5
+	layer      int // 1, 2 atau 3
+	bitrate    int // bps
+	sampleRate int
+	mono       bool
+}
+
+func (f mp3Frame) samplesPerFrame() int {
+	switch {
+	case f.layer == 1:
+		return 384
+	case f.layer == 3 && f.version != 3:
+		return 576
+	}
+	return 1152
+}
+
+func parseMP3Frame(h []byte) (mp3Frame, bool) {
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := int(h[1]>>3) & 3
+	layer := 4 - int(h[1]>>1)&3
+	rateIndex := int(h[2]>>2) & 3
+	if version == 1 || layer == 4 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+	mpeg1 := 0
+	if version == 3 {
+		mpeg1 = 1
+	}
+	bitrate := mp3Bitrates[[2]int{mpeg1, layer}][h[2]>>4] * 1000
+	if bitrate == 0 {
+		return mp3Frame{}, false
+	}
+	return mp3Frame{
+		version:    version,
+		layer:      layer,
+		bitrate:    bitrate,
+		sampleRate: mp3SampleRates[version][rateIndex],
+		mono:       h[3]>>6 == 3,
+	}, true
+}
+
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+func parseMP3(data []byte) AudioMetadata {
+	var meta AudioMetadata
+	audioStart := 0
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		tagSize := syncsafe(data[6:10])
+		end := min(len(data), 10+tagSize)
+		meta = parseID3v2(data[:end], data[3])
+		audioStart = end
+		if data[5]&0x10 != 0 { // Footer (ID3v2.4)
+			audioStart = min(len(data), end+10)
+		}
+	}
+
+	audioEnd := len(data)
+	if len(data) >= 128 && string(data[len(data)-128:len(data)-125]) == "TAG" {
+		tag := data[len(data)-128:]
+		if meta.Title == "" {
+			meta.Title = latin1(tag[3:33])
+		}
+		if meta.Artist == "" {
+			meta.Artist = latin1(tag[33:63])
+		}
+		if meta.Album == "" {
+			meta.Album = latin1(tag[63:93])
+		}
+		audioEnd -= 128
+	}
+
+	// Cari frame audio pertama setelah tag
+	for i := audioStart; i+4 <= audioEnd && i < audioStart+64*1024; i++ {
+		frame, ok := parseMP3Frame(data[i:])
+		if !ok {
+			continue
+		}
+		if meta.Duration == 0 {
+			meta.Duration = mp3Duration(data[i:audioEnd], frame)
+		}
+		break
+	}
+	return meta
+}
+
+// mp3Duration menghitung durasi dari header Xing/Info/VBRI (VBR), atau dari bitrate (CBR)
+func mp3Duration(audio []byte, frame mp3Frame) float64 {
+	sideInfo := 32
+	switch {
+	case frame.version == 3 && frame.mono:
+		sideInfo = 17
+	case frame.version != 3 && !frame.mono:
+		sideInfo = 17
+	case frame.version != 3 && frame.mono:
+		sideInfo = 9
+	}
+
+	frames := 0
+	if x := 4 + sideInfo; len(audio) >= x+12 && (string(audio[x:x+4]) == "Xing" || string(audio[x:x+4]) == "Info") {
+		if binary.BigEndian.Uint32(audio[x+4:])&1 != 0 {
+			frames = int(binary.BigEndian.Uint32(audio[x+8:]))
+		}
+	} else if v := 4 + 32; len(audio) >= v+18 && string(audio[v:v+4]) == "VBRI" {
+		frames = int(binary.BigEndian.Uint32(audio[v+14:]))
+	}
+	if frames > 0 && frame.sampleRate > 0 {
+		return float64(frames) * float64(frame.samplesPerFrame()) / float64(frame.sampleRate)
+	}
+	return float64(len(audio)) * 8 / float64(frame.bitrate)
+}
+
+// parseID3v2 membaca frame teks judul/artis/album (dan TLEN) dari tag ID3v2.2/2.3/2.4
+func parseID3v2(tag []byte, version byte) AudioMetadata {
+	var meta AudioMetadata
+	pos := 10
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+	if version >= 3 && tag[5]&0x40 != 0 && len(tag) >= 14 { // Extended header
+		if version == 4 {
+			pos += syncsafe(tag[10:14])
+		} else {
+			pos += 4 + int(binary.BigEndian.Uint32(tag[10:14]))
+		}
+	}
+
+	for pos+headerLen <= len(tag) {
+		id := string(tag[pos : pos+idLen])
+		if id[0] == 0 { // Padding
+			break
+		}
+		var size int
+		switch version {
+		case 2:
+			size = int(tag[pos+3])<<16 | int(tag[pos+4])<<8 | int(tag[pos+5])
+		case 4:
+			size = syncsafe(tag[pos+4 : pos+8])
+		default:
+			size = int(binary.BigEndian.Uint32(tag[pos+4 : pos+8]))
+		}
+		start := pos + headerLen
+		if size <= 0 || start+size > len(tag) {
+			break
+		}
+		body := tag[start : start+size]
+		pos = start + size
+
+		switch id {
+		case "TIT2", "TT2":
+			meta.Title = id3Text(body)
+		case "TPE1", "TP1":
+			meta.Artist = id3Text(body)
+		case "TALB", "TAL":
+			meta.Album = id3Text(body)
+		case "TLEN", "TLE":
+			var ms int
+			for _, r := range strings.TrimSpace(id3Text(body)) {
+				if r < '0' || r > '9' {
+					ms = 0
+					break
+				}
+				ms = ms*10 + int(r-'0')
+			}
+			meta.Duration = float64(ms) / 1000
+		}
+	}
+	return meta
+}
+
+// id3Text mendekode frame teks ID3 (byte pertama = encoding)
+func id3Text(body []byte) string {
+	if len(body) < 2 {
+		return ""
+	}
+	text := body[1:]
+	switch body[0] {
+	case 0:
+		return latin1(text)
+	case 1: // UTF-16 dengan BOM
+		if len(text) >= 2 && text[0] == 0xFE && text[1] == 0xFF {
+			return decodeUTF16(text[2:], binary.BigEndian)
+		}
+		if len(text) >= 2 && text[0] == 0xFF && text[1] == 0xFE {
+			text = text[2:]
+		}
+		return decodeUTF16(text, binary.LittleEndian)
+	case 2:
+		return decodeUTF16(text, binary.BigEndian)
+	}
+	return string(text) // 3 = UTF-8
+}
+
+func latin1(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func decodeUTF16(b []byte, order binary.ByteOrder) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u := order.Uint16(b[i:])
+		if u == 0 {
+			break
+		}
+		units = append(units, u)
+	}
+	return string(utf16.Decode(units))
+}
+
+// --- WAV ---
+
+func parseWAV(data []byte) AudioMetadata {
+	var meta AudioMetadata
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return meta
+	}
+
+	var byteRate, dataSize uint32
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		start := pos + 8
+		end := min(len(data), start+size)
+
+		switch id {
+		case "fmt ":
+			if end-start >= 12 {
+				byteRate = binary.LittleEndian.Uint32(data[start+8:])
+			}
+		case "data":
+			dataSize = uint32(size)
+		case "LIST":
+			if end-start >= 4 && string(data[start:start+4]) == "INFO" {
+				parseRIFFInfo(data[start+4:end], &meta)
+			}
+		}
+		pos = start + size + size%2 // Chunk selalu genap
+	}
+	if byteRate > 0 {
+		meta.Duration = float64(dataSize) / float64(byteRate)
+	}
+	return meta
+}
+
+func parseRIFFInfo(info []byte, meta *AudioMetadata) {
+	for pos := 0; pos+8 <= len(info); {
+		id := string(info[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(info[pos+4:]))
+		start := pos + 8
+		if start+size > len(info) {
+			return
+		}
+		value := string(info[start : start+size])
+		switch id {
+		case "INAM":
+			meta.Title = value
+		case "IART":
+			meta.Artist = value
+		case "IPRD":
+			meta.Album = value
+		}
+		pos = start + size + size%2
+	}
+}
+
+// --- FLAC ---
+
+func parseFLAC(data []byte) AudioMetadata {
+	var meta AudioMetadata
+	if len(data) < 4 || string(data[:4]) != "fLaC" {
+		return meta
+	}
+
+	for pos := 4; pos+4 <= len(data); {
+		last := data[pos]&0x80 != 0
+		blockType := data[pos] & 0x7F
+		size := int(data[pos+1])<<16 | int(data[pos+2])<<8 | int(data[pos+3])
+		start := pos + 4
+		if start+size > len(data) {
+			break
+		}
+		block := data[start : start+size]
+
+		switch blockType {
+		case 0: // STREAMINFO
+			if len(block) >= 18 {
+				sampleRate := int(block[10])<<12 | int(block[11])<<4 | int(block[12])>>4
+				totalSamples := uint64(block[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(block[14:18]))
+				if sampleRate > 0 {
+					meta.Duration = float64(totalSamples) / float64(sampleRate)
+				}
+			}
+		case 4: // VORBIS_COMMENT
+			parseVorbisComments(block, &meta)
+		}
+		if last {
+			break
+		}
+		pos = start + size
+	}
+	return meta
+}
+
+func parseVorbisComments(block []byte, meta *AudioMetadata) {
+	if len(block) < 4 {
+		return
+	}
+	pos := 4 + int(binary.LittleEndian.Uint32(block)) // Lewati vendor string
+	if pos+4 > len(block) {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(block[pos:]))
+	pos += 4
+	for i := 0; i < count && pos+4 <= len(block); i++ {
+		length := int(binary.LittleEndian.Uint32(block[pos:]))
+		pos += 4
+		if length < 0 || pos+length > len(block) {
+			return
+		}
+		key, value, _ := strings.Cut(string(block[pos:pos+length]), "=")
+		pos += length
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			meta.Title = value
+		case "ARTIST":
+			meta.Artist = value
+		case "ALBUM":
+			meta.Album = value
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Header frame MPEG1 Layer III, 128 kbps, 44.1 kHz
+var (
+	mp3StereoHeader = []byte{0xFF, 0xFB, 0x90, 0x00}
+	mp3MonoHeader   = []byte{0xFF, 0xFB, 0x90, 0xC0}
+)
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n>>21) & 0x7F, byte(n>>14) & 0x7F, byte(n>>7) & 0x7F, byte(n) & 0x7F}
+}
+
+// id3v2Tag membuat tag ID3v2 (versi 2, 3 atau 4) dari pasangan id & isi frame
+func id3v2Tag(version byte, frames ...[2]string) []byte {
+	var body []byte
+	for _, f := range frames {
+		id, content := f[0], []byte(f[1])
+		body = append(body, id...)
+		switch version {
+		case 2:
+			body = append(body, byte(len(content)>>16), byte(len(content)>>8), byte(len(content)))
+		case 4:
+			body = append(body, syncsafeBytes(len(content))...)
+			body = append(body, 0, 0)
+		default:
+			body = binary.BigEndian.AppendUint32(body, uint32(len(content)))
+			body = append(body, 0, 0)
+		}
+		body = append(body, content...)
+	}
+	body = append(body, make([]byte, 16)...) // Padding
+	tag := append([]byte{'I', 'D', '3', version, 0, 0}, syncsafeBytes(len(body))...)
+	return append(tag, body...)
+}
+
+// id3v1Tag membuat tag ID3v1 (128 byte) di akhir file
+func id3v1Tag(title, artist, album string) []byte {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	return tag
+}
+
+// mp3Frames membuat data audio sepanjang size dengan header frame di awal dan
+// (opsional) header VBR pada offset tertentu
+func mp3Frames(header []byte, size int, vbrOffset int, vbr []byte) []byte {
+	audio := make([]byte, size)
+	copy(audio, header)
+	if vbr != nil {
+		copy(audio[vbrOffset:], vbr)
+	}
+	return audio
+}
+
+func xingHeader(frames uint32) []byte {
+	h := append([]byte("Xing"), 0, 0, 0, 1) // Flag: jumlah frame tersedia
+	return binary.BigEndian.AppendUint32(h, frames)
+}
+
+func vbriHeader(frames uint32) []byte {
+	h := make([]byte, 18)
+	copy(h, "VBRI")
+	binary.BigEndian.PutUint32(h[14:], frames)
+	return h
+}
+
+func utf16LE(s string) string {
+	out := []byte{1, 0xFF, 0xFE}
+	for _, r := range s {
+		out = binary.LittleEndian.AppendUint16(out, uint16(r))
+	}
+	return string(out)
+}
+
+func riffChunk(id string, body []byte) []byte {
+	chunk := append([]byte(id), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(body)))
+	chunk = append(chunk, body...)
+	if len(body)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func wavFile(chunks ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	out := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+	return append(out, body...)
+}
+
+func wavFormat(byteRate uint32) []byte {
+	fmtBody := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtBody[0:], 1) // PCM
+	binary.LittleEndian.PutUint16(fmtBody[2:], 2) // Stereo
+	binary.LittleEndian.PutUint32(fmtBody[4:], 44100)
+	binary.LittleEndian.PutUint32(fmtBody[8:], byteRate)
+	return riffChunk("fmt ", fmtBody)
+}
+
+func flacBlock(blockType byte, last bool, body []byte) []byte {
+	header := []byte{blockType, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}
+	if last {
+		header[0] |= 0x80
+	}
+	return append(header, body...)
+}
+
+func flacStreamInfo(sampleRate int, totalSamples uint64) []byte {
+	info := make([]byte, 34)
+	info[10] = byte(sampleRate >> 12)
+	info[11] = byte(sampleRate >> 4)
+	info[12] = byte(sampleRate<<4) | 0x02 // + channel/bps bit
+	info[13] = 0xF0 | byte(totalSamples>>32)&0x0F
+	binary.BigEndian.PutUint32(info[14:], uint32(totalSamples))
+	return info
+}
+
+func vorbisComments(comments ...string) []byte {
+	vendor := "reference libFLAC 1.4.3"
+	block := binary.LittleEndian.AppendUint32(nil, uint32(len(vendor)))
+	block = append(block, vendor...)
+	block = binary.LittleEndian.AppendUint32(block, uint32(len(comments)))
+	for _, c := range comments {
+		block = binary.LittleEndian.AppendUint32(block, uint32(len(c)))
+		block = append(block, c...)
+	}
+	return block
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func TestParseAudioMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		want        AudioMetadata
+	}{
+		{
+			name:        "mp3 id3v2.3 with xing",
+			contentType: "audio/mpeg",
+			data: concat(
+				id3v2Tag(3, [2]string{"TIT2", "\x00Akad Nikah"}, [2]string{"TPE1", utf16LE("Raisa")}, [2]string{"TALB", "\x03Kali Kedua"}),
+				mp3Frames(mp3StereoHeader, 1024, 4+32, xingHeader(1000)),
+			),
+			want: AudioMetadata{Duration: 1000 * 1152 / 44100.0, Title: "Akad Nikah", Artist: "Raisa", Album: "Kali Kedua"},
+		},
+		{
+			name:        "mp3 mono xing",
+			contentType: "audio/mpeg",
+			data:        mp3Frames(mp3MonoHeader, 1024, 4+17, xingHeader(441)),
+			want:        AudioMetadata{Duration: 441 * 1152 / 44100.0},
+		},
+		{
+			name:        "mp3 vbri",
+			contentType: "audio/mpeg",
+			data:        mp3Frames(mp3StereoHeader, 1024, 4+32, vbriHeader(500)),
+			want:        AudioMetadata{Duration: 500 * 1152 / 44100.0},
+		},
+		{
+			name:        "mp3 id3v2.4 tlen wins over frames",
+			contentType: "audio/mpeg",
+			data: concat(
+				id3v2Tag(4, [2]string{"TIT2", "\x03Perfect"}, [2]string{"TLEN", "\x005000"}),
+				mp3Frames(mp3StereoHeader, 1024, 4+32, xingHeader(1000)),
+			),
+			want: AudioMetadata{Duration: 5, Title: "Perfect"},
+		},
+		{
+			name:        "mp3 id3v2.2",
+			contentType: "audio/mpeg",
+			data: concat(
+				id3v2Tag(2, [2]string{"TT2", "\x00Bunga"}, [2]string{"TP1", "\x00Band"}, [2]string{"TAL", "\x00Album"}),
+				mp3Frames(mp3StereoHeader, 16000, 0, nil),
+			),
+			want: AudioMetadata{Duration: 1, Title: "Bunga", Artist: "Band", Album: "Album"},
+		},
+		{
+			name:        "mp3 cbr with id3v1",
+			contentType: "audio/mpeg",
+			data:        concat(mp3Frames(mp3StereoHeader, 32000, 0, nil), id3v1Tag("Cinta", "Penyanyi", "Album Lama")),
+			want:        AudioMetadata{Duration: 2, Title: "Cinta", Artist: "Penyanyi", Album: "Album Lama"},
+		},
+		{
+			name:        "mp3 id3v2 takes precedence over id3v1",
+			contentType: "audio/mpeg",
+			data: concat(
+				id3v2Tag(3, [2]string{"TIT2", "\x00Baru"}),
+				mp3Frames(mp3StereoHeader, 16000, 0, nil),
+				id3v1Tag("Lama", "Artis", ""),
+			),
+			want: AudioMetadata{Duration: 1, Title: "Baru", Artist: "Artis"},
+		},
+		{
+			name:        "mp3 without frames",
+			contentType: "audio/mpeg",
+			data:        id3v2Tag(3, [2]string{"TIT2", "\x00Hanya Tag"}),
+			want:        AudioMetadata{Title: "Hanya Tag"},
+		},
+		{
+			name:        "wav with info list",
+			contentType: "audio/wav",
+			data: wavFile(
+				wavFormat(176400),
+				riffChunk("LIST", concat([]byte("INFO"), riffChunk("INAM", []byte("Lagu\x00")), riffChunk("IART", []byte("Orkes\x00")))),
+				riffChunk("data", make([]byte, 352800)),
+			),
+			want: AudioMetadata{Duration: 2, Title: "Lagu", Artist: "Orkes"},
+		},
+		{
+			name:        "wav truncated data chunk uses declared size",
+			contentType: "audio/wav",
+			data: func() []byte {
+				wav := wavFile(wavFormat(176400), riffChunk("data", make([]byte, 16)))
+				binary.LittleEndian.PutUint32(wav[len(wav)-20:], 176400*3)
+				return wav
+			}(),
+			want: AudioMetadata{Duration: 3},
+		},
+		{
+			name:        "flac with vorbis comments",
+			contentType: "audio/flac",
+			data: concat(
+				[]byte("fLaC"),
+				flacBlock(0, false, flacStreamInfo(44100, 441000)),
+				flacBlock(1, false, make([]byte, 8)), // Padding
+				flacBlock(4, true, vorbisComments("TITLE=Janji Suci", "artist=Yovie", "ALBUM=Nuno", "GENRE=Pop")),
+			),
+			want: AudioMetadata{Duration: 10, Title: "Janji Suci", Artist: "Yovie", Album: "Nuno"},
+		},
+		{
+			name:        "flac stops at last block",
+			contentType: "audio/flac",
+			data: concat(
+				[]byte("fLaC"),
+				flacBlock(0, true, flacStreamInfo(48000, 96000)),
+				flacBlock(4, true, vorbisComments("TITLE=Diabaikan")),
+			),
+			want: AudioMetadata{Duration: 2},
+		},
+		{
+			name:        "long tags are trimmed",
+			contentType: "audio/flac",
+			data: concat(
+				[]byte("fLaC"),
+				flacBlock(4, true, vorbisComments("TITLE=  "+strings.Repeat("é", 200)+"  ", "ARTIST=Nama\x00sampah")),
+			),
+			want: AudioMetadata{Title: strings.Repeat("é", 127), Artist: "Nama"},
+		},
+		{
+			name:        "unsupported format",
+			contentType: "audio/ogg",
+			data:        []byte("OggS\x00\x02"),
+			want:        AudioMetadata{},
+		},
+		{
+			name:        "wrong magic",
+			contentType: "audio/wav",
+			data:        []byte("fLaC not a wav file"),
+			want:        AudioMetadata{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAudioMetadata(tt.data, tt.contentType)
+			if math.Abs(got.Duration-tt.want.Duration) > 1e-6 {
+				t.Errorf("Duration = %v, want %v", got.Duration, tt.want.Duration)
+			}
+			if got.Title != tt.want.Title || got.Artist != tt.want.Artist || got.Album != tt.want.Album {
+				t.Errorf("tags = %q/%q/%q, want %q/%q/%q",
+					got.Title, got.Artist, got.Album, tt.want.Title, tt.want.Artist, tt.want.Album)
+			}
+		})
+	}
+}
+
+func FuzzParseAudioMetadata(f *testing.F) {
+	f.Add(concat(id3v2Tag(3, [2]string{"TIT2", "\x00Judul"}), mp3Frames(mp3StereoHeader, 256, 4+32, xingHeader(10))), "audio/mpeg")
+	f.Add(concat(id3v2Tag(4, [2]string{"TLEN", "\x001000"}), mp3Frames(mp3MonoHeader, 256, 4+32, vbriHeader(10))), "audio/mpeg")
+	f.Add(concat(mp3Frames(mp3StereoHeader, 256, 0, nil), id3v1Tag("a", "b", "c")), "audio/mpeg")
+	f.Add(wavFile(wavFormat(176400), riffChunk("LIST", concat([]byte("INFO"), riffChunk("INAM", []byte("x")))), riffChunk("data", make([]byte, 8))), "audio/wav")
+	f.Add(concat([]byte("fLaC"), flacBlock(0, false, flacStreamInfo(44100, 100)), flacBlock(4, true, vorbisComments("TITLE=x"))), "audio/flac")
+	f.Fuzz(func(t *testing.T, data []byte, contentType string) {
+		meta := ParseAudioMetadata(data, contentType)
+		if meta.Duration < 0 || math.IsNaN(meta.Duration) || math.IsInf(meta.Duration, 0) {
+			t.Fatalf("invalid duration %v", meta.Duration)
+		}
+		for _, tag := range []string{meta.Title, meta.Artist, meta.Album} {
+			if len(tag) > 255 || !utf8.ValidString(tag) {
+				t.Fatalf("invalid tag %q", tag)
+			}
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Batas ukuran potongan (chunk) pada upload bertahap
+const (
+	DefaultChunkSize = 8 * mb
+	MinChunkSize     = 1 * mb
+	MaxChunkSize     = 64 * mb
+)
+
+// ChunkDir adalah folder sementara untuk potongan upload bertahap.
+// Diatur lewat UPLOAD_TMP_DIR (default: folder temp sistem).
+func ChunkDir() string {
+	if dir := os.Getenv("UPLOAD_TMP_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "weddingpress-uploads")
+}
+
+func sessionDir(sessionID string) string {
+	return filepath.Join(ChunkDir(), sessionID)
+}
+
+func assembledPath(sessionID string) string {
+	return filepath.Join(sessionDir(sessionID), "assembled")
+}
+
+func partPath(sessionID string, index int) string {
+	return filepath.Join(sessionDir(sessionID), fmt.Sprintf("part-%06d", index))
+}
+
+// NormalizeSHA256 mengecek format checksum hex SHA-256 (kosong diperbolehkan)
+func NormalizeSHA256(sum string) (string, error) {
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	if sum == "" {
+		return "", nil
+	}
+	if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+		return "", &UploadError{http.StatusBadRequest, "sha256 must be a 64-character hex string"}
+	}
+	return sum, nil
+}
+
+// ChunkCount menghitung jumlah potongan untuk file sebesar size
+func ChunkCount(size, chunkSize int64) int {
+	return int((size + chunkSize - 1) / chunkSize)
+}
+
+// WriteChunk menyimpan satu potongan ke disk. Ukuran harus persis expectedSize;
+// jika partSHA256 diisi, checksum potongan juga dicek. Potongan yang sama boleh dikirim
+// ulang (misal setelah koneksi putus) dan akan menimpa yang lama.
+func WriteChunk(sessionID string, index int, r io.Reader, expectedSize int64, partSHA256 string) error {
+	if err := os.MkdirAll(sessionDir(sessionID), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(sessionDir(sessionID), "incoming-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Tidak berpengaruh setelah rename berhasil
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, expectedSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != expectedSize {
+		return &UploadError{http.StatusBadRequest,
+			fmt.Sprintf("Part %d must be exactly %d bytes (got %d)", index, expectedSize, written)}
+	}
+	if partSHA256 != "" && hex.EncodeToString(hash.Sum(nil)) != partSHA256 {
+		return &UploadError{http.StatusBadRequest, fmt.Sprintf("Checksum mismatch for part %d", index)}
+	}
+
+	// Rename bersifat atomik: potongan tidak pernah terlihat setengah jadi
+	return os.Rename(tmp.Name(), partPath(sessionID, index))
+}
+
+// ReceivedChunks mengembalikan indeks potongan yang sudah diterima (urut)
+func ReceivedChunks(sessionID string, totalChunks int) []int {
+	received := []int{}
+	for i := 0; i < totalChunks; i++ {
+		if _, err := os.Stat(partPath(sessionID, i)); err == nil {
+			received = append(received, i)
+		}
+	}
+	return received
+}
+
+// AssembleChunks menggabungkan semua potongan menjadi satu file sambil menghitung SHA-256-nya.
+// Mengembalikan path file hasil gabungan (di dalam folder sesi).
+func AssembleChunks(sessionID string, totalChunks int, totalSize int64) (string, string, error) {
+	target := assembledPath(sessionID)
+	out, err := os.Create(target)
+	if err != nil {
+		return "", "", err
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	writer := io.MultiWriter(out, hash)
+	var written int64
+	for i := 0; i < totalChunks; i++ {
+		part, err := os.Open(partPath(sessionID, i))
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", &UploadError{http.StatusConflict, fmt.Sprintf("Part %d has not been uploaded", i)}
+		}
+		if err != nil {
+			return "", "", err
+		}
+		n, err := io.Copy(writer, part)
+		part.Close()
+		if err != nil {
+			return "", "", err
+		}
+		written += n
+	}
+	if written != totalSize {
+		return "", "", &UploadError{http.StatusConflict,
+			fmt.Sprintf("Assembled file is %d bytes, expected %d", written, totalSize)}
+	}
+	if err := out.Close(); err != nil {
+		return "", "", err
+	}
+	return target, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// RemoveAssembled menghapus file gabungan sesi (misal setelah complete gagal),
+// potongan-potongannya tetap disimpan
+func RemoveAssembled(sessionID string) error {
+	if err := os.Remove(assembledPath(sessionID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// RemoveChunks menghapus semua file sementara milik sesi upload
+func RemoveChunks(sessionID string) error {
+	return os.RemoveAll(sessionDir(sessionID))
+}
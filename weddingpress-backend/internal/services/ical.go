@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CalendarEvent adalah satu acara yang siap ditulis ke file iCalendar (.ics)
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Latitude    *float64 // Opsional, ditulis sebagai GEO
+	Longitude   *float64
+	Start       time.Time // Zona waktu diambil dari Start.Location()
+	End         time.Time // Boleh kosong (zero)
+	AllDay      bool      // Jika true, hanya tanggal Start yang dipakai
+}
+
+const icsDateTime = "20060102T150405"
+const icsDate = "20060102"
+
+// BuildICalendar membuat dokumen iCalendar (RFC 5545) dari daftar acara.
+// Setiap zona waktu yang dipakai ditulis sebagai VTIMEZONE agar aplikasi kalender
+// menampilkan jam yang benar.
+func BuildICalendar(calendarName string, events []CalendarEvent) string {
+	var b strings.Builder
+	line := func(s string) { writeICSLine(&b, s) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//WeddingPress//Invitation Calendar//ID")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if calendarName != "" {
+		line("X-WR-CALNAME:" + escapeICSText(calendarName))
+	}
+
+	// Kumpulkan zona waktu beserta rentang acaranya
+	type tzRange struct {
+		loc      *time.Location
+		from, to time.Time
+	}
+	var zones []*tzRange
+	byName := map[string]*tzRange{}
+	for _, e := range events {
+		if e.AllDay || e.Start.Location() == time.UTC {
+			continue
+		}
+		end := e.End
+		if end.IsZero() {
+			end = e.Start
+		}
+		name := e.Start.Location().String()
+		if z, ok := byName[name]; ok {
+			if e.Start.Before(z.from) {
+				z.from = e.Start
+			}
+			if end.After(z.to) {
+				z.to = end
+			}
+			continue
+		}
+		z := &tzRange{loc: e.Start.Location(), from: e.Start, to: end}
+		byName[name] = z
+		zones = append(zones, z)
+	}
+	for _, z := range zones {
+		writeVTimezone(line, z.loc, z.from, z.to)
+	}
+
+	stamp := time.Now().UTC().Format(icsDateTime) + "Z"
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp)
+		switch {
+		case e.AllDay:
+			line("DTSTART;VALUE=DATE:" + e.Start.Format(icsDate))
+			line("DTEND;VALUE=DATE:" + e.Start.AddDate(0, 0, 1).Format(icsDate))
+		case e.Start.Location() == time.UTC:
+			line("DTSTART:" + e.Start.Format(icsDateTime) + "Z")
+			if !e.End.IsZero() {
+				line("DTEND:" + e.End.UTC().Format(icsDateTime) + "Z")
+			}
+		default:
+			tzid := e.Start.Location().String()
+			line("DTSTART;TZID=" + tzid + ":" + e.Start.Format(icsDateTime))
+			if !e.End.IsZero() {
+				line("DTEND;TZID=" + tzid + ":" + e.End.In(e.Start.Location()).Format(icsDateTime))
+			}
+		}
+		line("SUMMARY:" + escapeICSText(e.Summary))
+		if e.Location != "" {
+			line("LOCATION:" + escapeICSText(e.Location))
+		}
+		if e.Latitude != nil && e.Longitude != nil {
+			line(fmt.Sprintf("GEO:%.6f;%.6f", *e.Latitude, *e.Longitude))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeICSText(e.Description))
+		}
+		if e.URL != "" {
+			line("URL:" + e.URL)
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// writeVTimezone menulis definisi zona waktu untuk rentang tanggal yang dipakai acara,
+// termasuk perpindahan DST (jika zona tersebut punya DST).
+func writeVTimezone(line func(string), loc *time.Location, from, to time.Time) {
+	line("BEGIN:VTIMEZONE")
+	line("TZID:" + loc.String())
+
+	t := from.In(loc)
+	for {
+		name, offset := t.Zone()
+		start, end := t.ZoneBounds()
+
+		fromOffset := offset
+		onset := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+		if !start.IsZero() {
+			_, fromOffset = start.Add(-time.Second).Zone()
+			onset = start.UTC().Add(time.Duration(fromOffset) * time.Second)
+		}
+
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		line("BEGIN:" + kind)
+		line("DTSTART:" + onset.Format(icsDateTime))
+		line("TZOFFSETFROM:" + formatICSOffset(fromOffset))
+		line("TZOFFSETTO:" + formatICSOffset(offset))
+		if name != "" && !strings.HasPrefix(name, "+") && !strings.HasPrefix(name, "-") {
+			line("TZNAME:" + name)
+		}
+		line("END:" + kind)
+
+		if end.IsZero() || end.After(to) {
+			break
+		}
+		t = end
+	}
+
+	line("END:VTIMEZONE")
+}
+
+func formatICSOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, (seconds%3600)/60)
+}
+
+// escapeICSText meng-escape karakter khusus pada nilai TEXT (RFC 5545 3.3.11)
+func escapeICSText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+	return replacer.Replace(s)
+}
+
+// writeICSLine menulis satu baris konten dengan CRLF, dilipat setiap 75 oktet
+// tanpa memotong karakter UTF-8 (RFC 5545 3.1)
+func writeICSLine(b *strings.Builder, s string) {
+	const limit = 75
+	first := true
+	for len(s) > 0 {
+		size := limit
+		if !first {
+			size = limit - 1 // Spasi di awal baris lanjutan ikut dihitung
+		}
+		if len(s) <= size {
+			if !first {
+				b.WriteString(" ")
+			}
+			b.WriteString(s)
+			break
+		}
+		cut := size
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		if !first {
+			b.WriteString(" ")
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n")
+		s = s[cut:]
+		first = false
+	}
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // Registrasi decoder
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Ukuran varian gambar (sisi terpanjang, dalam piksel)
+const (
+	VariantThumb  = "thumb"
+	VariantMedium = "medium"
+	VariantFull   = "full"
+)
+
+var imageVariantSizes = []struct {
+	Name    string
+	MaxSide int
+	Quality int
+}{
+	{VariantFull, 2560, 85},
+	{VariantMedium, 1280, 82},
+	{VariantThumb, 400, 78},
+}
+
+// Gambar di atas batas ini ditolak sebelum di-decode (mencegah "decompression bomb").
+// Gambar hasil decode bisa memakan 4 byte per piksel, dan upload massal memproses
+// beberapa gambar sekaligus, jadi batas ini menentukan puncak pemakaian memori.
+const maxImagePixels = 40_000_000
+
+// ImageVariant adalah satu ukuran gambar hasil pipeline (selalu JPEG)
+type ImageVariant struct {
+	Name   string
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// ProcessableImage mengecek apakah tipe gambar diproses pipeline.
+// GIF (animasi) tidak di-encode ulang; metadatanya dibuang dengan StripGIFMetadata.
+func ProcessableImage(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp":
+		return true
+	}
+	return false
+}
+
+// ProcessImage memutar gambar sesuai orientasi EXIF, lalu membuat varian full, medium
+// dan thumb dalam JPEG. Encoding ulang membuang semua metadata (EXIF, GPS, dll).
+// Varian tidak pernah lebih besar dari gambar asli.
+// Catatan: library standar Go tidak punya encoder WebP, jadi semua varian berupa JPEG.
+func ProcessImage(data []byte) ([]ImageVariant, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &UploadError{http.StatusUnsupportedMediaType, "Image could not be decoded"}
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, &UploadError{http.StatusRequestEntityTooLarge, "Image dimensions are too large"}
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &UploadError{http.StatusUnsupportedMediaType, "Image could not be decoded"}
+	}
+
+	// Perkecil ke ukuran "full" dulu, baru diratakan & diputar, agar salinan berikutnya
+	// berukuran maksimal 2560px (bukan ukuran asli)
+	orientation := jpegOrientation(data)
+	current := resizeToFit(src, imageVariantSizes[0].MaxSide)
+	src = nil // Gambar asli tidak dipakai lagi, biarkan GC membebaskannya
+	current = applyOrientation(flattenImage(current), orientation)
+
+	variants := make([]ImageVariant, 0, len(imageVariantSizes))
+	for _, size := range imageVariantSizes {
+		// Setiap varian diperkecil dari varian sebelumnya (lebih cepat daripada dari aslinya)
+		current = resizeToFit(current, size.MaxSide)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, current, &jpeg.Options{Quality: size.Quality}); err != nil {
+			return nil, err
+		}
+		bounds := current.Bounds()
+		variants = append(variants, ImageVariant{
+			Name:   size.Name,
+			Data:   buf.Bytes(),
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+		})
+	}
+	return variants, nil
+}
+
+// flattenImage meratakan gambar di atas latar putih (JPEG tidak punya transparansi).
+// Hasil resizeToFit (RGBA milik pipeline) diratakan langsung tanpa salinan baru.
+func flattenImage(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	if rgba, ok := src.(*image.RGBA); ok && bounds.Min == (image.Point{}) && rgba.Stride == 4*bounds.Dx() {
+		for i := 0; i < len(rgba.Pix); i += 4 {
+			// Pix berisi warna premultiplied: tambahkan putih sebanyak sisa alpha
+			if a := rgba.Pix[i+3]; a != 0xFF {
+				rest := 0xFF - a
+				rgba.Pix[i] += rest
+				rgba.Pix[i+1] += rest
+				rgba.Pix[i+2] += rest
+				rgba.Pix[i+3] = 0xFF
+			}
+		}
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
+
+// resizeToFit memperkecil gambar agar sisi terpanjangnya maksimal maxSide
+func resizeToFit(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// applyOrientation memutar/membalik gambar sesuai tag Orientation EXIF (1-8) tanpa
+// mengalokasikan gambar baru. img harus RGBA dengan Rect berawal di (0,0) dan Stride 4*lebar.
+func applyOrientation(img *image.RGBA, orientation int) *image.RGBA {
+	switch orientation {
+	case 2: // Cermin horizontal
+		flipHorizontal(img)
+	case 3: // Putar 180
+		flipHorizontal(img)
+		flipVertical(img)
+	case 4: // Cermin vertikal
+		flipVertical(img)
+	case 5: // Transpose
+		img = transpose(img)
+	case 6: // Putar 90 searah jarum jam
+		img = transpose(img)
+		flipHorizontal(img)
+	case 7: // Transverse
+		img = transpose(img)
+		flipHorizontal(img)
+		flipVertical(img)
+	case 8: // Putar 90 berlawanan jarum jam
+		img = transpose(img)
+		flipVertical(img)
+	}
+	return img
+}
+
+// flipHorizontal mencerminkan setiap baris piksel
+func flipHorizontal(img *image.RGBA) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	var tmp [4]byte
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+4*w]
+		for l, r := 0, 4*(w-1); l < r; l, r = l+4, r-4 {
+			copy(tmp[:], row[l:l+4])
+			copy(row[l:l+4], row[r:r+4])
+			copy(row[r:r+4], tmp[:])
+		}
+	}
+}
+
+// flipVertical menukar baris atas dengan baris bawah
+func flipVertical(img *image.RGBA) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	tmp := make([]byte, 4*w)
+	for top, bottom := 0, h-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := img.Pix[top*img.Stride : top*img.Stride+4*w]
+		b := img.Pix[bottom*img.Stride : bottom*img.Stride+4*w]
+		copy(tmp, a)
+		copy(a, b)
+		copy(b, tmp)
+	}
+}
+
+// transpose menukar sumbu x & y di buffer yang sama (transposisi in-place dengan
+// mengikuti siklus permutasi; piksel ke-i pindah ke posisi i*h mod (n-1))
+func transpose(img *image.RGBA) *image.RGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	n := w * h
+	if n > 2 {
+		visited := make([]uint64, (n+63)/64)
+		var carry, tmp [4]byte
+		for start := 1; start < n-1; start++ {
+			if visited[start/64]&(1<<(start%64)) != 0 {
+				continue
+			}
+			copy(carry[:], img.Pix[4*start:4*start+4])
+			cur := start
+			for {
+				next := int(int64(cur) * int64(h) % int64(n-1))
+				copy(tmp[:], img.Pix[4*next:4*next+4])
+				copy(img.Pix[4*next:4*next+4], carry[:])
+				carry = tmp
+				visited[next/64] |= 1 << (next % 64)
+				cur = next
+				if cur == start {
+					break
+				}
+			}
+		}
+	}
+	return &image.RGBA{Pix: img.Pix, Stride: 4 * h, Rect: image.Rect(0, 0, h, w)}
+}
+
+// StripGIFMetadata membuang blok komentar dan application extension (misal XMP) dari GIF
+// tanpa men-decode frame-nya. Hanya ekstensi loop animasi (NETSCAPE2.0/ANIMEXTS1.0) yang dipertahankan.
+func StripGIFMetadata(data []byte) ([]byte, error) {
+	invalid := &UploadError{http.StatusUnsupportedMediaType, "GIF could not be read"}
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, invalid
+	}
+
+	// Header + logical screen descriptor, lalu global color table (jika ada)
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&0x07 + 1)
+	}
+	if pos > len(data) {
+		return nil, invalid
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:pos]...)
+
+	// skipSubBlocks mengembalikan posisi setelah rangkaian sub-blok (diakhiri blok berukuran 0)
+	skipSubBlocks := func(p int) (int, bool) {
+		for p < len(data) {
+			size := int(data[p])
+			p++
+			if size == 0 {
+				return p, true
+			}
+			p += size
+		}
+		return p, false
+	}
+
+	for pos < len(data) {
+		start := pos
+		switch data[pos] {
+		case 0x3B: // Trailer
+			return append(out, 0x3B), nil
+		case 0x21: // Extension
+			if pos+2 > len(data) {
+				return nil, invalid
+			}
+			label := data[pos+1]
+			end, ok := skipSubBlocks(pos + 2)
+			if !ok {
+				return nil, invalid
+			}
+			keep := label == 0xF9 || label == 0x01 // Graphic control & plain text
+			if label == 0xFF && pos+3 < len(data) && data[pos+2] == 11 && pos+14 <= len(data) {
+				app := string(data[pos+3 : pos+14])
+				keep = app == "NETSCAPE2.0" || app == "ANIMEXTS1.0"
+			}
+			if keep {
+				out = append(out, data[start:end]...)
+			}
+			pos = end
+		case 0x2C: // Image descriptor (+ local color table), lalu data LZW
+			if pos+10 > len(data) {
+				return nil, invalid
+			}
+			p := pos + 10
+			if flags := data[pos+9]; flags&0x80 != 0 {
+				p += 3 << (flags&0x07 + 1)
+			}
+			end, ok := skipSubBlocks(p + 1) // +1: LZW minimum code size
+			if !ok {
+				return nil, invalid
+			}
+			out = append(out, data[start:end]...)
+			pos = end
+		default:
+			return nil, invalid
+		}
+	}
+	return nil, invalid // Tidak ada trailer
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen EXIF (APP1) JPEG.
+// Mengembalikan 1 (normal) jika tidak ada EXIF atau datanya tidak valid.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	r := bytes.NewReader(data[2:])
+	for {
+		var marker [2]byte
+		if _, err := io.ReadFull(r, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		if marker[1] == 0xDA || marker[1] == 0xD9 { // Awal data gambar: tidak ada EXIF
+			return 1
+		}
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil || length < 2 {
+			return 1
+		}
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return 1
+		}
+		if marker[1] == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			if o, err := tiffOrientation(segment[6:]); err == nil {
+				return o
+			}
+			return 1
+		}
+	}
+}
+
+// tiffOrientation mencari tag Orientation di IFD0 header TIFF milik EXIF
+func tiffOrientation(tiff []byte) (int, error) {
+	if len(tiff) < 8 {
+		return 0, errors.New("short tiff header")
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, errors.New("invalid byte order")
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 0, errors.New("invalid ifd offset")
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value, nil
+			}
+			return 0, errors.New("invalid orientation")
+		}
+	}
+	return 1, nil
+}
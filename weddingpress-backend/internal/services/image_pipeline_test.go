@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// buildTIFF membuat header TIFF EXIF dengan satu entri IFD0 (tag, nilai SHORT)
+func buildTIFF(order binary.ByteOrder, tag, value uint16) []byte {
+	buf := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(buf, "II")
+	} else {
+		copy(buf, "MM")
+	}
+	order.PutUint16(buf[2:], 42)
+	order.PutUint32(buf[4:], 8)
+	order.PutUint16(buf[8:], 1)
+	order.PutUint16(buf[10:], tag)
+	order.PutUint16(buf[12:], 3) // SHORT
+	order.PutUint32(buf[14:], 1)
+	order.PutUint16(buf[18:], value)
+	return buf
+}
+
+// buildJPEG membungkus segmen-segmen APP menjadi awal file JPEG (SOI + segmen + SOS)
+func buildJPEG(segments ...[]byte) []byte {
+	out := []byte{0xFF, 0xD8}
+	for _, seg := range segments {
+		out = append(out, seg...)
+	}
+	return append(out, 0xFF, 0xDA, 0x00, 0x02)
+}
+
+func segment(marker byte, body []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(body)+2))
+	return append(seg, body...)
+}
+
+func exifSegment(tiff []byte) []byte {
+	return segment(0xE1, append([]byte("Exif\x00\x00"), tiff...))
+}
+
+func TestTIFFOrientation(t *testing.T) {
+	tests := []struct {
+		name    string
+		tiff    []byte
+		want    int
+		wantErr bool
+	}{
+		{"little endian", buildTIFF(binary.LittleEndian, 0x0112, 6), 6, false},
+		{"big endian", buildTIFF(binary.BigEndian, 0x0112, 8), 8, false},
+		{"tag missing", buildTIFF(binary.LittleEndian, 0x010F, 3), 1, false},
+		{"value out of range", buildTIFF(binary.BigEndian, 0x0112, 9), 0, true},
+		{"short header", []byte("II*\x00"), 0, true},
+		{"invalid byte order", append([]byte("XX"), buildTIFF(binary.BigEndian, 0x0112, 3)[2:]...), 0, true},
+		{"ifd offset past end", func() []byte {
+			b := buildTIFF(binary.LittleEndian, 0x0112, 3)
+			binary.LittleEndian.PutUint32(b[4:], 1000)
+			return b
+		}(), 0, true},
+		{"truncated entry", buildTIFF(binary.LittleEndian, 0x0112, 3)[:16], 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tiffOrientation(tt.tiff)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tiffOrientation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("tiffOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"exif orientation", buildJPEG(exifSegment(buildTIFF(binary.BigEndian, 0x0112, 6))), 6},
+		{"exif after app0", buildJPEG(segment(0xE0, []byte("JFIF\x00")), exifSegment(buildTIFF(binary.LittleEndian, 0x0112, 3))), 3},
+		{"no exif", buildJPEG(segment(0xE0, []byte("JFIF\x00"))), 1},
+		{"xmp app1 ignored", buildJPEG(segment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00"))), 1},
+		{"invalid exif", buildJPEG(exifSegment([]byte("garbage!"))), 1},
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"truncated segment", buildJPEG(exifSegment(buildTIFF(binary.BigEndian, 0x0112, 6)))[:12], 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// orientedSource mengembalikan koordinat piksel sumber untuk piksel (x, y) hasil orientasi
+// (definisi tag Orientation EXIF; w & h adalah ukuran gambar sumber)
+func orientedSource(orientation, x, y, w, h int) (int, int) {
+	switch orientation {
+	case 2:
+		return w - 1 - x, y
+	case 3:
+		return w - 1 - x, h - 1 - y
+	case 4:
+		return x, h - 1 - y
+	case 5:
+		return y, x
+	case 6:
+		return y, h - 1 - x
+	case 7:
+		return w - 1 - y, h - 1 - x
+	case 8:
+		return w - 1 - y, x
+	}
+	return x, y
+}
+
+func TestApplyOrientation(t *testing.T) {
+	sizes := [][2]int{{1, 1}, {1, 5}, {4, 3}, {7, 2}, {16, 9}}
+	for _, size := range sizes {
+		w, h := size[0], size[1]
+		for orientation := 1; orientation <= 8; orientation++ {
+			src := image.NewRGBA(image.Rect(0, 0, w, h))
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					src.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 0xFF})
+				}
+			}
+
+			got := applyOrientation(src, orientation)
+			wantW, wantH := w, h
+			if orientation >= 5 {
+				wantW, wantH = h, w
+			}
+			if got.Rect.Dx() != wantW || got.Rect.Dy() != wantH {
+				t.Fatalf("%dx%d orientation %d: size %v, want %dx%d", w, h, orientation, got.Rect.Size(), wantW, wantH)
+			}
+			for y := 0; y < wantH; y++ {
+				for x := 0; x < wantW; x++ {
+					sx, sy := orientedSource(orientation, x, y, w, h)
+					if c := got.RGBAAt(x, y); int(c.R) != sx || int(c.G) != sy {
+						t.Fatalf("%dx%d orientation %d: pixel (%d,%d) came from (%d,%d), want (%d,%d)",
+							w, h, orientation, x, y, c.R, c.G, sx, sy)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestStripGIFMetadata(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black, color.White})
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{img, img}, Delay: []int{10, 10}}); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	// Sisipkan komentar dan blok XMP setelah logical screen descriptor (encoder Go tidak
+	// menulis global color table, jadi blok pertama ada di offset 13)
+	comment := append([]byte{0x21, 0xFE, 5}, append([]byte("hello"), 0)...)
+	xmp := append([]byte{0x21, 0xFF, 11}, append([]byte("XMP DataXMP"), 3, 'g', 'p', 's', 0)...)
+	var data []byte
+	data = append(data, encoded[:13]...)
+	data = append(data, comment...)
+	data = append(data, xmp...)
+	data = append(data, encoded[13:]...)
+
+	stripped, err := StripGIFMetadata(data)
+	if err != nil {
+		t.Fatalf("StripGIFMetadata() error = %v", err)
+	}
+	if !bytes.Equal(stripped, encoded) {
+		t.Errorf("StripGIFMetadata() did not restore the original GIF (got %d bytes, want %d)", len(stripped), len(encoded))
+	}
+	if decoded, err := gif.DecodeAll(bytes.NewReader(stripped)); err != nil || len(decoded.Image) != 2 || decoded.LoopCount != 0 {
+		t.Errorf("stripped GIF does not decode as the original animation: %v", err)
+	}
+
+	for name, bad := range map[string][]byte{
+		"not a gif":     []byte("\x89PNG\r\n\x1a\n0000000"),
+		"no trailer":    encoded[:len(encoded)-1],
+		"truncated":     data[:20],
+		"unknown block": append(append([]byte{}, encoded[:13]...), 0x42),
+	} {
+		if _, err := StripGIFMetadata(bad); err == nil {
+			t.Errorf("StripGIFMetadata(%s) error = nil, want error", name)
+		}
+	}
+}
+
+func FuzzJPEGOrientation(f *testing.F) {
+	f.Add(buildJPEG(exifSegment(buildTIFF(binary.BigEndian, 0x0112, 6))))
+	f.Add(buildJPEG(segment(0xE0, []byte("JFIF\x00"))))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if o := jpegOrientation(data); o < 1 || o > 8 {
+			t.Fatalf("jpegOrientation() = %d, want 1-8", o)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ".../data=!3m1!4b1!4m6!3m5!...!3d-8.6705!4d115.2126" (titik lokasi tempat)
+	mapsPlacePattern = regexp.MustCompile(`!3d(-?\d+(?:\.\d+)?)!4d(-?\d+(?:\.\d+)?)`)
+	// ".../@-8.6705,115.2126,17z" (pusat tampilan peta)
+	mapsViewportPattern = regexp.MustCompile(`@(-?\d+(?:\.\d+)?),(-?\d+(?:\.\d+)?)`)
+	// "-8.6705,115.2126" (nilai parameter q/ll/destination atau segmen path)
+	coordinatePairPattern = regexp.MustCompile(`^\s*(-?\d+(?:\.\d+)?)\s*,\s*(-?\d+(?:\.\d+)?)\s*$`)
+)
+
+// ValidCoordinates mengecek rentang latitude/longitude
+func ValidCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// ParseMapsCoordinates mengambil koordinat dari link Google Maps yang umum dipakai:
+// "/place/.../!3d<lat>!4d<lng>", "/@<lat>,<lng>,17z", "?q=<lat>,<lng>", "?ll=", "?query=",
+// "?destination=" dan "/dir/.../<lat>,<lng>". Link pendek (maps.app.goo.gl) tidak bisa
+// dibaca tanpa mengikuti redirect, jadi hasilnya ok=false.
+func ParseMapsCoordinates(rawURL string) (lat, lng float64, ok bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return 0, 0, false
+	}
+
+	decoded, err := url.QueryUnescape(rawURL)
+	if err != nil {
+		decoded = rawURL
+	}
+
+	if m := mapsPlacePattern.FindStringSubmatch(decoded); m != nil {
+		if lat, lng, ok = parseCoordinatePair(m[1], m[2]); ok {
+			return lat, lng, true
+		}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err == nil {
+		query := u.Query()
+		for _, key := range []string{"q", "query", "ll", "destination", "daddr"} {
+			if m := coordinatePairPattern.FindStringSubmatch(query.Get(key)); m != nil {
+				if lat, lng, ok = parseCoordinatePair(m[1], m[2]); ok {
+					return lat, lng, true
+				}
+			}
+		}
+	}
+
+	if m := mapsViewportPattern.FindStringSubmatch(decoded); m != nil {
+		if lat, lng, ok = parseCoordinatePair(m[1], m[2]); ok {
+			return lat, lng, true
+		}
+	}
+
+	if err == nil {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			segment, _ := url.PathUnescape(segments[i])
+			if m := coordinatePairPattern.FindStringSubmatch(segment); m != nil {
+				if lat, lng, ok = parseCoordinatePair(m[1], m[2]); ok {
+					return lat, lng, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func parseCoordinatePair(latStr, lngStr string) (float64, float64, bool) {
+	lat, errLat := strconv.ParseFloat(latStr, 64)
+	lng, errLng := strconv.ParseFloat(lngStr, 64)
+	if errLat != nil || errLng != nil || !ValidCoordinates(lat, lng) {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+func formatCoordinates(lat, lng float64) string {
+	return fmt.Sprintf("%s,%s", strconv.FormatFloat(lat, 'f', -1, 64), strconv.FormatFloat(lng, 'f', -1, 64))
+}
+
+// GoogleMapsDirectionsURL membuat link petunjuk arah Google Maps ke koordinat
+func GoogleMapsDirectionsURL(lat, lng float64) string {
+	return "https://www.google.com/maps/dir/?api=1&destination=" + url.QueryEscape(formatCoordinates(lat, lng))
+}
+
+// GoogleMapsDirectionsToAddress membuat link petunjuk arah Google Maps ke alamat (tanpa koordinat)
+func GoogleMapsDirectionsToAddress(address string) string {
+	return "https://www.google.com/maps/dir/?api=1&destination=" + url.QueryEscape(address)
+}
+
+// WazeDirectionsURL membuat link navigasi Waze ke koordinat
+func WazeDirectionsURL(lat, lng float64) string {
+	return "https://waze.com/ul?navigate=yes&ll=" + url.QueryEscape(formatCoordinates(lat, lng))
+}
+
+// AppleMapsDirectionsURL membuat link petunjuk arah Apple Maps ke koordinat
+func AppleMapsDirectionsURL(lat, lng float64, label string) string {
+	params := url.Values{}
+	params.Set("daddr", formatCoordinates(lat, lng))
+	if label != "" {
+		params.Set("q", label)
+	}
+	return "https://maps.apple.com/?" + params.Encode()
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// MaxMarkdownLength adalah batas panjang teks Markdown (dalam karakter)
+const MaxMarkdownLength = 10000
+
+// Skema URL yang boleh dipakai di link Markdown
+var allowedLinkSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+	"tel":    true,
+}
+
+// SanitizeMarkdown membersihkan teks Markdown dari tamu/admin sebelum disimpan:
+// karakter kontrol dibuang, baris baru diseragamkan dan panjangnya dibatasi.
+// Teks disimpan apa adanya ("I <3 you" tetap "I <3 you"); HTML mentah baru dibuang saat
+// dirender oleh RenderMarkdown.
+//
+// Teks lalu di-parse dengan goldmark (CommonMark) dan setiap link, gambar & autolink
+// diperiksa tujuannya; skema berbahaya (javascript:, data:, vbscript:, ...) ditolak dengan error.
+// Fungsi ini idempoten: hasilnya aman untuk disanitasi ulang.
+func SanitizeMarkdown(input string) (string, error) {
+	s := strings.ReplaceAll(input, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+
+	s = strings.TrimSpace(s)
+	if runes := []rune(s); len(runes) > MaxMarkdownLength {
+		s = string(runes[:MaxMarkdownLength])
+	}
+
+	if dest, ok := firstUnsafeLink([]byte(s)); !ok {
+		return "", fmt.Errorf("link %q is not allowed (use http, https, mailto or tel)", dest)
+	}
+	return s, nil
+}
+
+// markdownRenderer merender Markdown ke HTML dalam mode aman bawaan goldmark (HTML mentah
+// diganti komentar "raw HTML omitted"), ditambah pemeriksaan skema link di safeLinkTransformer
+var markdownRenderer = goldmark.New(goldmark.WithParserOptions(
+	parser.WithASTTransformers(util.Prioritized(safeLinkTransformer{}, 1000)),
+))
+
+// RenderMarkdown mengubah teks Markdown yang tersimpan menjadi HTML yang aman ditampilkan
+func RenderMarkdown(source string) string {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(source), &buf); err != nil {
+		return html.EscapeString(source)
+	}
+	return buf.String()
+}
+
+// safeLinkTransformer mengganti tujuan link/gambar yang tidak aman dengan "#" dan mengubah
+// autolink tidak aman menjadi teks biasa (untuk data lama yang tersimpan sebelum validasi)
+type safeLinkTransformer struct{}
+
+func (safeLinkTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+	var autoLinks []*ast.AutoLink
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Link:
+			if !isSafeLinkURL(string(node.Destination)) {
+				node.Destination = []byte("#")
+			}
+		case *ast.Image:
+			if !isSafeLinkURL(string(node.Destination)) {
+				node.Destination = []byte("#")
+			}
+		case *ast.AutoLink:
+			if !isSafeLinkURL(string(node.URL(source))) {
+				autoLinks = append(autoLinks, node)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, node := range autoLinks {
+		node.Parent().ReplaceChild(node.Parent(), node, ast.NewString(node.Label(source)))
+	}
+}
+
+// firstUnsafeLink mem-parse Markdown dan mengembalikan tujuan link/gambar pertama yang tidak aman
+// (termasuk link referensi "[teks][ref]", yang sudah di-resolve parser menjadi node link biasa)
+func firstUnsafeLink(source []byte) (string, bool) {
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+	var unsafe string
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var dest string
+		switch node := n.(type) {
+		case *ast.Link:
+			dest = string(node.Destination)
+		case *ast.Image:
+			dest = string(node.Destination)
+		case *ast.AutoLink:
+			dest = string(node.URL(source))
+		default:
+			return ast.WalkContinue, nil
+		}
+		if !isSafeLinkURL(dest) {
+			unsafe = dest
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return unsafe, unsafe == ""
+}
+
+// isSafeLinkURL mengizinkan URL relatif/anchor dan skema yang ada di allowedLinkSchemes.
+// Entity HTML & spasi dibuang dulu agar "java&#58;script:" atau "java script:" ikut tertangkap.
+func isSafeLinkURL(raw string) bool {
+	normalized := strings.ToLower(html.UnescapeString(raw))
+	normalized = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, normalized)
+
+	colon := strings.Index(normalized, ":")
+	if colon < 0 {
+		return true // Tanpa skema: relatif atau anchor
+	}
+	if slash := strings.IndexAny(normalized, "/?#"); slash >= 0 && slash < colon {
+		return true // ":" ada di path/query, bukan skema
+	}
+	return allowedLinkSchemes[normalized[:colon]]
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeMarkdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"plain text", "Kami bertemu di kampus.", "Kami bertemu di kampus.", false},
+		{"safe link", "[foto](https://example.com/a.jpg)", "[foto](https://example.com/a.jpg)", false},
+		{"safe image with title", `![kami](https://example.com/a.jpg "Bali")`, `![kami](https://example.com/a.jpg "Bali")`, false},
+		{"relative and anchor links", "[a](/story) [b](#akad)", "[a](/story) [b](#akad)", false},
+		{"mailto and tel", "[email](mailto:a@b.c) [wa](tel:+62812)", "[email](mailto:a@b.c) [wa](tel:+62812)", false},
+		{"colon in path", "[x](/a:b)", "[x](/a:b)", false},
+		{"newlines and control characters", "a\r\nb\rc\x00d\x07", "a\nb\ncd", false},
+		{"raw text kept", "I <3 you & <b>kamu</b>", "I <3 you & <b>kamu</b>", false},
+		{"angle bracket safe destination", "[x](<https://example.com/a b>)", "[x](<https://example.com/a b>)", false},
+		{"javascript link", "[x](javascript:alert(1))", "", true},
+		{"nested brackets", "[a [b] c](javascript:alert(1))", "", true},
+		{"deeply nested brackets", "[a [b [c] d] e](javascript:alert(1))", "", true},
+		{"image", "![x](javascript:alert(1))", "", true},
+		{"data uri image", "![x](data:image/svg+xml;base64,PHN2Zz4=)", "", true},
+		{"vbscript", "[x](vbscript:msgbox(1))", "", true},
+		{"mixed case scheme", "[x](JaVaScRiPt:alert(1))", "", true},
+		{"decimal entity in scheme", "[x](java&#115;cript:alert(1))", "", true},
+		{"hex entity in scheme", "[x](&#x6A;avascript:alert(1))", "", true},
+		{"named entity colon", "[x](javascript&colon;alert(1))", "", true},
+		{"angle bracket destination", "[x](<javascript:alert(1)>)", "", true},
+		{"autolink", "<javascript:alert(1)>", "", true},
+		{"link with title", `[x](javascript:alert(1) "judul")`, "", true},
+		{"reference link", "[x][r]\n\n[r]: javascript:alert(1)", "", true},
+		{"collapsed reference link", "[r][]\n\n[r]: javascript:alert(1)", "", true},
+		{"shortcut reference link", "[r]\n\n[R]: javascript:alert(1)", "", true},
+		{"link inside emphasis", "*[x](javascript:alert(1))*", "", true},
+		{"link inside list", "- satu\n- [x](javascript:alert(1))", "", true},
+		{"unused reference definition", "teks\n\n[r]: https://example.com", "teks\n\n[r]: https://example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SanitizeMarkdown(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SanitizeMarkdown(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SanitizeMarkdown(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeMarkdownLength(t *testing.T) {
+	got, err := SanitizeMarkdown(strings.Repeat("é", MaxMarkdownLength+10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len([]rune(got)); n != MaxMarkdownLength {
+		t.Errorf("length = %d runes, want %d", n, MaxMarkdownLength)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"text with less-than", "I <3 you", "<p>I &lt;3 you</p>\n"},
+		{"emphasis and link", "*Bali* [foto](https://example.com)", "<p><em>Bali</em> <a href=\"https://example.com\">foto</a></p>\n"},
+		{"image", "![kami](https://example.com/a.jpg)", "<p><img src=\"https://example.com/a.jpg\" alt=\"kami\"></p>\n"},
+		{"raw html omitted", "<script>alert(1)</script>", "<!-- raw HTML omitted -->\n"},
+		{"inline html omitted", "a <img src=x onerror=alert(1)> b", "<p>a <!-- raw HTML omitted --> b</p>\n"},
+		{"unsafe link neutralized", "[x](javascript:alert(1))", "<p><a href=\"#\">x</a></p>\n"},
+		{"nested brackets neutralized", "[a [b] c](javascript:alert(1))", "<p><a href=\"#\">a [b] c</a></p>\n"},
+		{"entity scheme neutralized", "![x](java&#115;cript:alert(1))", "<p><img src=\"#\" alt=\"x\"></p>\n"},
+		{"unsafe autolink becomes text", "<javascript:alert(1)>", "<p>javascript:alert(1)</p>\n"},
+		{"safe autolink", "<https://example.com>", "<p><a href=\"https://example.com\">https://example.com</a></p>\n"},
+		{"legacy escaped text", "I &lt;3 you", "<p>I &lt;3 you</p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.input); got != tt.want {
+				t.Errorf("RenderMarkdown(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
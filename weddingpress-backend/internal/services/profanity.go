@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultBlocklist adalah kata kasar bahasa Indonesia & Inggris yang ditandai otomatis.
+// Wedding bisa menambah kata sendiri (Wedding.GuestBookBlocklist).
+var DefaultBlocklist = []string{
+	// Indonesia
+	"anjing", "anjir", "asu", "babi", "bajingan", "bangsat", "bego", "brengsek", "goblok",
+	"jancok", "jancuk", "kampret", "keparat", "kontol", "lonte", "memek", "ngentot", "pepek",
+	"perek", "tai", "tolol",
+	// Inggris
+	"asshole", "bastard", "bitch", "cunt", "dick", "fuck", "motherfucker", "shit", "slut", "whore",
+}
+
+// Pengganti karakter "leetspeak" yang sering dipakai untuk menyamarkan kata (misal "4nj1ng")
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// normalizeWord membuat kata lowercase, mengganti leetspeak, dan memadatkan huruf berulang
+// ("anjiiing" -> "anjing") agar variasi ejaan tetap terdeteksi
+func normalizeWord(word string) string {
+	word = leetReplacer.Replace(strings.ToLower(word))
+	var b strings.Builder
+	var last rune
+	for _, r := range word {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// ParseBlocklist memecah daftar kata (dipisah koma atau baris baru) menjadi kata unik
+func ParseBlocklist(raw string) []string {
+	seen := map[string]bool{}
+	words := []string{}
+	for _, word := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' || r == ';' }) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Kata umum yang kebetulan berbentuk kata terlarang + akhiran ("babi" + "es")
+var blocklistExceptions = map[string]bool{"babies": true}
+
+// Akhiran yang masih dianggap kata yang sama ("fucking", "bangsatnya")
+var blockedWordSuffixes = []string{"", "s", "es", "ed", "er", "ers", "ing", "y", "nya", "lah", "kan", "an", "in"}
+
+// FindBlockedWords mengembalikan kata terlarang (dari DefaultBlocklist + extra) yang muncul
+// di pesan. Kata dicocokkan per kata utuh, boleh diikuti akhiran umum (blockedWordSuffixes).
+func FindBlockedWords(message string, extra []string) []string {
+	blocked := map[string]string{} // kata ternormalisasi -> kata asli di daftar
+	for _, list := range [][]string{DefaultBlocklist, extra} {
+		for _, word := range list {
+			if n := normalizeWord(word); n != "" {
+				blocked[n] = word
+			}
+		}
+	}
+
+	tokens := strings.FieldsFunc(message, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '$'
+	})
+	found := map[string]bool{}
+	for _, token := range tokens {
+		token = normalizeWord(token)
+		if blocklistExceptions[token] {
+			continue
+		}
+		for n, word := range blocked {
+			if !strings.HasPrefix(token, n) {
+				continue
+			}
+			rest := token[len(n):]
+			for _, suffix := range blockedWordSuffixes {
+				// Huruf ganda di sambungan kata & akhiran ikut dipadatkan ("kontollah" -> "kontolah").
+				// Hanya untuk akhiran 3 huruf ke atas, agar "babi" + "in" tidak cocok dengan "babin".
+				if rest == suffix || (len(suffix) >= 3 && suffix[0] == n[len(n)-1] && rest == suffix[1:]) {
+					found[word] = true
+					break
+				}
+			}
+		}
+	}
+
+	words := make([]string, 0, len(found))
+	for word := range found {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
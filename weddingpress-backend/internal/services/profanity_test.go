@@ -0,0 +1,58 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindBlockedWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		extra   []string
+		want    []string
+	}{
+		{"clean message", "Selamat menempuh hidup baru, semoga sakinah mawaddah warahmah!", nil, []string{}},
+		{"exact word", "dasar bangsat", nil, []string{"bangsat"}},
+		{"case insensitive", "ANJING kamu", nil, []string{"anjing"}},
+		{"repeated letters", "anjiiiing", nil, []string{"anjing"}},
+		{"leetspeak", "4nj1ng b4ngs4t", nil, []string{"anjing", "bangsat"}},
+		{"punctuation around word", "...goblok!!!", nil, []string{"goblok"}},
+		{"english suffix", "this is fucking great", nil, []string{"fuck"}},
+		{"indonesian suffix", "bangsatnya datang", nil, []string{"bangsat"}},
+		{"suffix sharing the last letter", "kontollah", nil, []string{"kontol"}},
+		{"double letters in blocklist word", "you asshole", nil, []string{"asshole"}},
+		{"each word reported once", "tolol tolol TOLOL", nil, []string{"tolol"}},
+		{"sorted result", "shit bego anjing", nil, []string{"anjing", "bego", "shit"}},
+		{"word inside longer word", "Dickens menulis klasik di Scunthorpe", nil, []string{}},
+		{"unknown suffix", "taiwan", nil, []string{}},
+		{"short suffix sharing the last letter", "babin tain", nil, []string{}},
+		{"exception", "cute babies", nil, []string{}},
+		{"extra blocklist", "dasar mantan", []string{"mantan"}, []string{"mantan"}},
+		{"extra blocklist with suffix", "mantannya datang", []string{"Mantan"}, []string{"Mantan"}},
+		{"empty extra word ignored", "halo semua", []string{""}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindBlockedWords(tt.message, tt.extra); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindBlockedWords(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlocklist(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"", []string{}},
+		{"mantan, Pelakor,mantan", []string{"mantan", "pelakor"}},
+		{"satu\ndua;tiga, ,\n", []string{"satu", "dua", "tiga"}},
+	}
+	for _, tt := range tests {
+		if got := ParseBlocklist(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseBlocklist(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
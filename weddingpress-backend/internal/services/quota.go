@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Paket langganan wedding
+const (
+	PlanFree     = "free"
+	PlanPremium  = "premium"
+	PlanBusiness = "business"
+)
+
+// Kuota default per paket (MB). Bisa diganti lewat env STORAGE_QUOTA_<PAKET>_MB,
+// misal STORAGE_QUOTA_FREE_MB=250; nilai 0 berarti tanpa batas.
+var defaultStorageQuotaMB = map[string]int64{
+	PlanFree:     500,
+	PlanPremium:  5 * 1024,
+	PlanBusiness: 20 * 1024,
+}
+
+// NormalizePlan mengembalikan nama paket yang dikenali (paket kosong/tidak dikenal = free)
+func NormalizePlan(plan string) string {
+	plan = strings.ToLower(strings.TrimSpace(plan))
+	if _, ok := defaultStorageQuotaMB[plan]; !ok {
+		return PlanFree
+	}
+	return plan
+}
+
+// StorageQuota mengembalikan batas storage (byte) untuk paket; 0 berarti tanpa batas
+func StorageQuota(plan string) int64 {
+	plan = NormalizePlan(plan)
+	if raw := os.Getenv("STORAGE_QUOTA_" + strings.ToUpper(plan) + "_MB"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil && n >= 0 {
+			return n * mb
+		}
+	}
+	return defaultStorageQuotaMB[plan] * mb
+}
+
+// StorageUsage adalah pemakaian storage sebuah wedding dibanding kuota paketnya
+type StorageUsage struct {
+	Plan       string `json:"plan"`
+	UsedBytes  int64  `json:"used_bytes"`
+	LimitBytes int64  `json:"limit_bytes"` // 0 = tanpa batas
+}
+
+// Unlimited mengecek apakah paket tidak punya batas storage
+func (u StorageUsage) Unlimited() bool {
+	return u.LimitBytes <= 0
+}
+
+// RemainingBytes mengembalikan sisa kuota (-1 jika tanpa batas)
+func (u StorageUsage) RemainingBytes() int64 {
+	if u.Unlimited() {
+		return -1
+	}
+	return max(0, u.LimitBytes-u.UsedBytes)
+}
+
+// CheckQuota mengembalikan *UploadError (413) jika file sebesar size melebihi sisa kuota
+func (u StorageUsage) CheckQuota(size int64) error {
+	if u.Unlimited() || u.UsedBytes+size <= u.LimitBytes {
+		return nil
+	}
+	return &UploadError{http.StatusRequestEntityTooLarge, fmt.Sprintf(
+		"Storage quota exceeded: %s of %s used on the %s plan, this file needs %s",
+		formatBytes(u.UsedBytes), formatBytes(u.LimitBytes), u.Plan, formatBytes(size))}
+}
+
+func formatBytes(bytes int64) string {
+	if bytes < mb {
+		return fmt.Sprintf("%d KB", (bytes+1023)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(bytes)/mb)
+}
@@ -0,0 +1,137 @@
+package services
+
+import "time"
+
+// Status acara / pernikahan pada jadwal
+const (
+	ScheduleUpcoming    = "upcoming"
+	ScheduleOngoing     = "ongoing"
+	SchedulePast        = "past"
+	ScheduleUnscheduled = "unscheduled" // Belum ada acara
+)
+
+// ScheduleEvent adalah data acara yang dibutuhkan untuk menghitung jadwal
+type ScheduleEvent struct {
+	ID    uint
+	Name  string
+	Start time.Time
+	End   *time.Time // Kosong = sampai akhir hari (di zona waktu Start)
+}
+
+// EventSchedule adalah status satu acara relatif terhadap waktu sekarang
+type EventSchedule struct {
+	EventID           uint      `json:"event_id"`
+	Name              string    `json:"name"`
+	StartsAt          time.Time `json:"starts_at"`
+	EndsAt            time.Time `json:"ends_at"`
+	Status            string    `json:"status"`              // upcoming, ongoing, past
+	SecondsUntilStart int64     `json:"seconds_until_start"` // 0 jika sudah dimulai
+	SecondsUntilEnd   int64     `json:"seconds_until_end"`   // 0 jika sudah selesai
+}
+
+// Countdown adalah sisa waktu menuju acara berikutnya, sudah dipecah untuk tampilan
+type Countdown struct {
+	Days    int64 `json:"days"`
+	Hours   int64 `json:"hours"`
+	Minutes int64 `json:"minutes"`
+	Seconds int64 `json:"seconds"`
+}
+
+// Schedule adalah ringkasan jadwal pernikahan untuk template undangan
+type Schedule struct {
+	Now          time.Time       `json:"now"`
+	Timezone     string          `json:"timezone"`
+	Status       string          `json:"status"` // Status keseluruhan pernikahan
+	CurrentEvent *EventSchedule  `json:"current_event"`
+	NextEvent    *EventSchedule  `json:"next_event"`
+	Countdown    *Countdown      `json:"countdown"` // Menuju NextEvent (null jika tidak ada)
+	Events       []EventSchedule `json:"events"`
+}
+
+// ComputeSchedule menghitung status setiap acara dan status pernikahan pada waktu now.
+// Semua waktu dikembalikan di zona waktu loc (zona waktu wedding).
+// Pernikahan "upcoming" sebelum acara pertama dimulai, "ongoing" sampai acara terakhir
+// selesai, lalu "past".
+func ComputeSchedule(events []ScheduleEvent, now time.Time, loc *time.Location) Schedule {
+	now = now.In(loc)
+	schedule := Schedule{
+		Now:      now.Truncate(time.Second),
+		Timezone: loc.String(),
+		Status:   ScheduleUnscheduled,
+		Events:   make([]EventSchedule, 0, len(events)),
+	}
+
+	var first, last time.Time
+	for i, e := range events {
+		end := endOfDay(e.Start)
+		if e.End != nil && e.End.After(e.Start) {
+			end = *e.End
+		}
+
+		item := EventSchedule{
+			EventID:  e.ID,
+			Name:     e.Name,
+			StartsAt: e.Start.In(loc),
+			EndsAt:   end.In(loc),
+		}
+		switch {
+		case now.Before(e.Start):
+			item.Status = ScheduleUpcoming
+			item.SecondsUntilStart = int64(e.Start.Sub(now).Seconds())
+			item.SecondsUntilEnd = int64(end.Sub(now).Seconds())
+		case now.Before(end):
+			item.Status = ScheduleOngoing
+			item.SecondsUntilEnd = int64(end.Sub(now).Seconds())
+		default:
+			item.Status = SchedulePast
+		}
+		schedule.Events = append(schedule.Events, item)
+
+		if i == 0 || e.Start.Before(first) {
+			first = e.Start
+		}
+		if i == 0 || end.After(last) {
+			last = end
+		}
+	}
+
+	for i := range schedule.Events {
+		item := &schedule.Events[i]
+		if item.Status == ScheduleOngoing && schedule.CurrentEvent == nil {
+			schedule.CurrentEvent = item
+		}
+		if item.Status == ScheduleUpcoming && (schedule.NextEvent == nil || item.StartsAt.Before(schedule.NextEvent.StartsAt)) {
+			schedule.NextEvent = item
+		}
+	}
+	if schedule.NextEvent != nil {
+		schedule.Countdown = newCountdown(schedule.NextEvent.SecondsUntilStart)
+	}
+
+	if len(events) > 0 {
+		switch {
+		case now.Before(first):
+			schedule.Status = ScheduleUpcoming
+		case now.Before(last):
+			schedule.Status = ScheduleOngoing
+		default:
+			schedule.Status = SchedulePast
+		}
+	}
+	return schedule
+}
+
+// endOfDay mengembalikan tengah malam berikutnya di zona waktu t
+func endOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+}
+
+func newCountdown(seconds int64) *Countdown {
+	return &Countdown{
+		Days:    seconds / 86400,
+		Hours:   (seconds % 86400) / 3600,
+		Minutes: (seconds % 3600) / 60,
+		Seconds: seconds % 60,
+	}
+}
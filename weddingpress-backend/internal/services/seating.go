@@ -0,0 +1,109 @@
+package services
+
+import (
+	"sort"
+)
+
+// SeatingParty adalah satu unit yang harus duduk bersama (satu tamu beserta rombongannya)
+type SeatingParty struct {
+	GuestID uint
+	Group   string
+	Seats   int
+}
+
+// SeatingSlot adalah meja yang tersedia untuk penempatan otomatis
+type SeatingSlot struct {
+	TableID  uint
+	Capacity int
+	Used     int            // Kursi yang sudah terisi sebelum penempatan otomatis
+	Groups   map[string]int // Grup yang sudah duduk di meja ini (jumlah kursi per grup)
+}
+
+func (s *SeatingSlot) free() int { return s.Capacity - s.Used }
+
+// AutoAssignSeats menempatkan tamu ke meja dengan mengelompokkan berdasarkan Guest.Group.
+// Grup terbesar ditempatkan lebih dulu; setiap tamu diutamakan duduk di meja yang sudah
+// berisi grupnya, lalu di meja kosong, lalu di meja mana pun yang masih muat.
+// Mengembalikan peta GuestID -> TableID serta daftar GuestID yang tidak mendapat meja.
+func AutoAssignSeats(slots []SeatingSlot, parties []SeatingParty) (map[uint]uint, []uint) {
+	tables := make([]*SeatingSlot, len(slots))
+	for i := range slots {
+		slot := slots[i]
+		if slot.Groups == nil {
+			slot.Groups = map[string]int{}
+		}
+		tables[i] = &slot
+	}
+
+	// Kelompokkan tamu per grup
+	byGroup := map[string][]SeatingParty{}
+	groupSeats := map[string]int{}
+	var groupNames []string
+	for _, p := range parties {
+		if _, ok := byGroup[p.Group]; !ok {
+			groupNames = append(groupNames, p.Group)
+		}
+		byGroup[p.Group] = append(byGroup[p.Group], p)
+		groupSeats[p.Group] += p.Seats
+	}
+
+	// Grup besar lebih dulu agar lebih mudah disatukan dalam satu meja
+	sort.SliceStable(groupNames, func(i, j int) bool {
+		if groupSeats[groupNames[i]] != groupSeats[groupNames[j]] {
+			return groupSeats[groupNames[i]] > groupSeats[groupNames[j]]
+		}
+		return groupNames[i] < groupNames[j]
+	})
+
+	assigned := map[uint]uint{}
+	var unassigned []uint
+	for _, group := range groupNames {
+		members := byGroup[group]
+		sort.SliceStable(members, func(i, j int) bool { return members[i].Seats > members[j].Seats })
+
+		for _, p := range members {
+			table := pickTable(tables, group, p.Seats)
+			if table == nil {
+				unassigned = append(unassigned, p.GuestID)
+				continue
+			}
+			table.Used += p.Seats
+			table.Groups[group] += p.Seats
+			assigned[p.GuestID] = table.TableID
+		}
+	}
+
+	return assigned, unassigned
+}
+
+// pickTable memilih meja terbaik untuk tamu dari grup tertentu
+func pickTable(tables []*SeatingSlot, group string, seats int) *SeatingSlot {
+	var sameGroup, empty, fallback *SeatingSlot
+	for _, t := range tables {
+		if t.free() < seats {
+			continue
+		}
+		switch {
+		case group != "" && t.Groups[group] > 0:
+			if sameGroup == nil || t.free() > sameGroup.free() {
+				sameGroup = t
+			}
+		case t.Used == 0:
+			if empty == nil || t.Capacity > empty.Capacity {
+				empty = t
+			}
+		default:
+			if fallback == nil || t.free() > fallback.free() {
+				fallback = t
+			}
+		}
+	}
+
+	if sameGroup != nil {
+		return sameGroup
+	}
+	if empty != nil {
+		return empty
+	}
+	return fallback
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAutoAssignSeats(t *testing.T) {
+	tests := []struct {
+		name         string
+		slots        []SeatingSlot
+		parties      []SeatingParty
+		wantPlaced   map[uint]uint
+		wantUnplaced []uint
+	}{
+		{
+			name:  "groups packed at their own table",
+			slots: []SeatingSlot{{TableID: 1, Capacity: 10}, {TableID: 2, Capacity: 10}},
+			parties: []SeatingParty{
+				{GuestID: 1, Group: "keluarga", Seats: 3},
+				{GuestID: 2, Group: "kantor", Seats: 4},
+				{GuestID: 3, Group: "keluarga", Seats: 3},
+				{GuestID: 4, Group: "kantor", Seats: 4},
+				{GuestID: 5, Group: "keluarga", Seats: 2},
+			},
+			wantPlaced: map[uint]uint{2: 1, 4: 1, 1: 2, 3: 2, 5: 2},
+		},
+		{
+			name: "group joins the table it already sits at",
+			slots: []SeatingSlot{
+				{TableID: 1, Capacity: 10},
+				{TableID: 2, Capacity: 8, Used: 2, Groups: map[string]int{"teman": 2}},
+			},
+			parties:    []SeatingParty{{GuestID: 7, Group: "teman", Seats: 2}},
+			wantPlaced: map[uint]uint{7: 2},
+		},
+		{
+			name: "empty table preferred over a table of another group",
+			slots: []SeatingSlot{
+				{TableID: 1, Capacity: 10, Used: 2, Groups: map[string]int{"kantor": 2}},
+				{TableID: 2, Capacity: 6},
+			},
+			parties:    []SeatingParty{{GuestID: 8, Group: "teman", Seats: 2}},
+			wantPlaced: map[uint]uint{8: 2},
+		},
+		{
+			name:  "group split when one table is not enough",
+			slots: []SeatingSlot{{TableID: 1, Capacity: 4}, {TableID: 2, Capacity: 4}},
+			parties: []SeatingParty{
+				{GuestID: 1, Group: "keluarga", Seats: 3},
+				{GuestID: 2, Group: "keluarga", Seats: 3},
+			},
+			wantPlaced: map[uint]uint{1: 1, 2: 2},
+		},
+		{
+			name:         "party larger than any table",
+			slots:        []SeatingSlot{{TableID: 1, Capacity: 10}},
+			parties:      []SeatingParty{{GuestID: 1, Group: "keluarga", Seats: 12}, {GuestID: 2, Group: "keluarga", Seats: 2}},
+			wantPlaced:   map[uint]uint{2: 1},
+			wantUnplaced: []uint{1},
+		},
+		{
+			name: "party never split across tables",
+			slots: []SeatingSlot{
+				{TableID: 1, Capacity: 6, Used: 3, Groups: map[string]int{"": 3}},
+				{TableID: 2, Capacity: 6, Used: 3, Groups: map[string]int{"": 3}},
+			},
+			parties:      []SeatingParty{{GuestID: 9, Seats: 4}, {GuestID: 10, Seats: 3}},
+			wantPlaced:   map[uint]uint{10: 1},
+			wantUnplaced: []uint{9},
+		},
+		{
+			name:         "no tables",
+			parties:      []SeatingParty{{GuestID: 1, Seats: 1}},
+			wantPlaced:   map[uint]uint{},
+			wantUnplaced: []uint{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placed, unplaced := AutoAssignSeats(tt.slots, tt.parties)
+			if !reflect.DeepEqual(placed, tt.wantPlaced) {
+				t.Errorf("placed = %v, want %v", placed, tt.wantPlaced)
+			}
+			if !reflect.DeepEqual(unplaced, tt.wantUnplaced) {
+				t.Errorf("unplaced = %v, want %v", unplaced, tt.wantUnplaced)
+			}
+		})
+	}
+}
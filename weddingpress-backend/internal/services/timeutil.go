@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimezone dipakai jika wedding/acara belum punya zona waktu
+const DefaultTimezone = "Asia/Jakarta"
+
+// LoadTimezone memuat zona waktu IANA; nama kosong berarti DefaultTimezone
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultTimezone
+	}
+	return time.LoadLocation(name)
+}
+
+// ParseClock membaca jam "HH:MM" (atau "HH.MM" / "HH:MM:SS")
+func ParseClock(value string) (hour, minute int, ok bool) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, ".", ":"))
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	h, errH := strconv.Atoi(parts[0])
+	m, errM := strconv.Atoi(parts[1])
+	if errH != nil || errM != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, 0, false
+	}
+	return h, m, true
+}
+
+// CombineDateAndClock menggabungkan tanggal (hanya Y-M-D yang dipakai) dengan jam "HH:MM"
+// di zona waktu loc. Jam kosong/tidak valid dianggap 00:00.
+func CombineDateAndClock(date time.Time, clock string, loc *time.Location) time.Time {
+	year, month, day := date.Date()
+	hour, minute, _ := ParseClock(clock)
+	return time.Date(year, month, day, hour, minute, 0, 0, loc)
+}
+
+// DateOnly mengembalikan tanggal lokal t sebagai tengah malam UTC (untuk kolom DATE)
+func DateOnly(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
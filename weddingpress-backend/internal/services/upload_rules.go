@@ -0,0 +1,225 @@
+package services
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const mb = 1 << 20
+
+// Kategori file hasil deteksi isi
+const (
+	CategoryImage = "image"
+	CategoryVideo = "video"
+	CategoryAudio = "audio"
+)
+
+// Tujuan upload yang dikenali (field "purpose" pada form upload)
+const (
+	PurposeGeneral = "general"
+	PurposeCover   = "cover"   // Foto sampul & foto mempelai
+	PurposeMusic   = "music"   // Musik latar undangan
+	PurposeGallery = "gallery" // Foto/video galeri & story
+	PurposeQRIS    = "qris"    // Gambar QRIS rekening hadiah
+
+	PurposeGuestPhoto = "guest_photo" // Foto kiriman tamu (endpoint publik)
+)
+
+var (
+	// HEIC tidak diterima: belum ada decoder, jadi EXIF/GPS-nya tidak bisa dibuang
+	imageTypes = []string{"image/jpeg", "image/png", "image/webp", "image/gif"}
+	videoTypes = []string{"video/mp4", "video/quicktime", "video/webm"}
+	audioTypes = []string{"audio/mpeg", "audio/x-m4a", "audio/mp4", "audio/aac", "audio/ogg", "audio/wav", "audio/flac"}
+)
+
+// UploadRule membatasi tipe (hasil sniffing isi file) dan ukuran per kategori
+type UploadRule struct {
+	AllowedTypes    []string
+	MaxBytes        map[string]int64 // Per kategori (image/video/audio), upload multipart biasa
+	ChunkedMaxBytes map[string]int64 // Batas upload bertahap per kategori (kosong = sama dengan MaxBytes)
+}
+
+// UploadRules berisi aturan untuk setiap tujuan upload
+var UploadRules = map[string]UploadRule{
+	PurposeGeneral: {
+		AllowedTypes: imageTypes,
+		MaxBytes:     map[string]int64{CategoryImage: 10 * mb},
+	},
+	PurposeCover: {
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/webp"},
+		MaxBytes:     map[string]int64{CategoryImage: 10 * mb},
+	},
+	PurposeMusic: {
+		AllowedTypes: audioTypes,
+		MaxBytes:     map[string]int64{CategoryAudio: 20 * mb},
+	},
+	PurposeGallery: {
+		AllowedTypes:    append(append([]string{}, imageTypes...), videoTypes...),
+		MaxBytes:        map[string]int64{CategoryImage: 15 * mb, CategoryVideo: 200 * mb},
+		ChunkedMaxBytes: map[string]int64{CategoryImage: 15 * mb, CategoryVideo: 500 * mb}, // Video besar hanya lewat upload bertahap
+	},
+	PurposeQRIS: {
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/webp"},
+		MaxBytes:     map[string]int64{CategoryImage: 5 * mb},
+	},
+	PurposeGuestPhoto: {
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/webp"},
+		MaxBytes:     map[string]int64{CategoryImage: 10 * mb},
+	},
+}
+
+// UploadError adalah kesalahan validasi upload beserta status HTTP-nya (400/413/415)
+type UploadError struct {
+	Status  int
+	Message string
+}
+
+func (e *UploadError) Error() string { return e.Message }
+
+// UploadInfo adalah hasil validasi file upload
+type UploadInfo struct {
+	Purpose     string
+	ContentType string // Dari isi file, bukan dari header yang dikirim browser
+	Extension   string // Misal ".jpg"
+	Category    string // image, video atau audio
+}
+
+// limits mengembalikan batas ukuran per kategori untuk upload multipart atau bertahap
+func (r UploadRule) limits(chunked bool) map[string]int64 {
+	if chunked && r.ChunkedMaxBytes != nil {
+		return r.ChunkedMaxBytes
+	}
+	return r.MaxBytes
+}
+
+// maxBytes mengembalikan batas ukuran terbesar dari aturan (upload multipart)
+func (r UploadRule) maxBytes() int64 {
+	return largestLimit(r.limits(false))
+}
+
+func largestLimit(limits map[string]int64) int64 {
+	var largest int64
+	for _, size := range limits {
+		if size > largest {
+			largest = size
+		}
+	}
+	return largest
+}
+
+// MaxUploadBytes mengembalikan batas ukuran terbesar dari semua tujuan upload
+// (untuk membatasi body request sebelum form dibaca)
+func MaxUploadBytes() int64 {
+	var largest int64
+	for _, rule := range UploadRules {
+		if size := rule.maxBytes(); size > largest {
+			largest = size
+		}
+	}
+	return largest
+}
+
+func normalizePurpose(purpose string) string {
+	purpose = strings.ToLower(strings.TrimSpace(purpose))
+	if purpose == "" {
+		return PurposeGeneral
+	}
+	return purpose
+}
+
+// CategoryOf mengembalikan kategori (image/video/audio) dari content type
+func CategoryOf(contentType string) string {
+	category, _, _ := strings.Cut(contentType, "/")
+	return category
+}
+
+func formatMB(bytes int64) string {
+	return fmt.Sprintf("%d MB", bytes/mb)
+}
+
+// FileOpener membuka isi file yang akan diunggah (multipart.FileHeader.Open, atau os.Open
+// untuk file hasil upload bertahap)
+type FileOpener func() (multipart.File, error)
+
+// OpenLocalFile membuat FileOpener untuk file di disk
+func OpenLocalFile(path string) FileOpener {
+	return func() (multipart.File, error) { return os.Open(path) }
+}
+
+// CheckChunkedUploadSize mengecek ukuran file terhadap batas terbesar upload bertahap
+// tujuan upload (tanpa membaca isi file, saat sesi upload dimulai)
+func CheckChunkedUploadSize(size int64, purpose string) error {
+	return checkUploadSize(size, purpose, true)
+}
+
+func checkUploadSize(size int64, purpose string, chunked bool) error {
+	purpose = normalizePurpose(purpose)
+	rule, ok := UploadRules[purpose]
+	if !ok {
+		return &UploadError{http.StatusBadRequest, fmt.Sprintf("Unknown upload purpose %q", purpose)}
+	}
+	if size <= 0 {
+		return &UploadError{http.StatusBadRequest, "Uploaded file is empty"}
+	}
+	if limit := largestLimit(rule.limits(chunked)); size > limit {
+		return &UploadError{http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File is too large (max %s for %s uploads)", formatMB(limit), purpose)}
+	}
+	return nil
+}
+
+// ValidateUpload mengecek file terhadap aturan tujuan upload sebelum diunggah:
+// ukuran, lalu tipe berdasarkan isi file (magic bytes) memakai mimetype.
+func ValidateUpload(file *multipart.FileHeader, purpose string) (UploadInfo, error) {
+	return validateUploadContent(file.Open, file.Size, purpose, false)
+}
+
+func validateUploadContent(open FileOpener, size int64, purpose string, chunked bool) (UploadInfo, error) {
+	if err := checkUploadSize(size, purpose, chunked); err != nil {
+		return UploadInfo{}, err
+	}
+	purpose = normalizePurpose(purpose)
+	rule := UploadRules[purpose]
+
+	src, err := open()
+	if err != nil {
+		return UploadInfo{}, &UploadError{http.StatusBadRequest, "Failed to read uploaded file"}
+	}
+	defer src.Close()
+
+	detected, err := mimetype.DetectReader(src)
+	if err != nil {
+		return UploadInfo{}, &UploadError{http.StatusBadRequest, "Failed to read uploaded file"}
+	}
+
+	var contentType string
+	for _, allowed := range rule.AllowedTypes {
+		if detected.Is(allowed) {
+			contentType = allowed
+			break
+		}
+	}
+	if contentType == "" {
+		return UploadInfo{}, &UploadError{http.StatusUnsupportedMediaType,
+			fmt.Sprintf("File type %s is not allowed for %s uploads (allowed: %s)",
+				detected.String(), purpose, strings.Join(rule.AllowedTypes, ", "))}
+	}
+
+	category := CategoryOf(contentType)
+	if limit := rule.limits(chunked)[category]; size > limit {
+		return UploadInfo{}, &UploadError{http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File is too large (max %s for %s files)", formatMB(limit), category)}
+	}
+
+	return UploadInfo{
+		Purpose:     purpose,
+		ContentType: contentType,
+		Extension:   detected.Extension(),
+		Category:    category,
+	}, nil
+}
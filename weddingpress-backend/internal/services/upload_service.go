@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"weddingpress_backend/internal/storage"
+)
+
+// UploadedFile adalah hasil upload. Untuk gambar JPEG/PNG/WebP, file utama adalah
+// varian "full" (sudah diputar sesuai EXIF dan tanpa metadata), ditambah varian lebih kecil.
+type UploadedFile struct {
+	storage.Object
+	Provider string // Nama driver storage tempat file disimpan
+	Category string // image, video atau audio
+	Width    int    // Hanya untuk gambar yang diproses
+	Height   int
+	Thumb    *storage.Object
+	Medium   *storage.Object
+}
+
+// UploadFile memvalidasi file sesuai tujuan upload (lihat UploadRules), lalu mengunggahnya
+// ke storage aktif (Cloudinary/lokal/S3) di dalam folder tertentu.
+// Kesalahan validasi dikembalikan sebagai *UploadError.
+func UploadFile(file *multipart.FileHeader, folder, purpose string) (UploadedFile, error) {
+	return uploadFrom(file.Open, file.Size, folder, purpose, false)
+}
+
+// UploadChunked sama seperti UploadFile untuk file hasil upload bertahap yang sudah
+// dirangkai di disk; batas ukurannya memakai ChunkedMaxBytes
+func UploadChunked(path string, size int64, folder, purpose string) (UploadedFile, error) {
+	return uploadFrom(OpenLocalFile(path), size, folder, purpose, true)
+}
+
+// uploadTimeout memberi waktu lebih lama untuk file besar (60 detik + 2 detik per MB)
+func uploadTimeout(size int64) time.Duration {
+	return 60*time.Second + time.Duration(size/mb)*2*time.Second
+}
+
+// uploadFrom memvalidasi lalu mengunggah isi file yang dibaca dari open
+func uploadFrom(open FileOpener, size int64, folder, purpose string, chunked bool) (UploadedFile, error) {
+	info, err := validateUploadContent(open, size, purpose, chunked)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	store, err := storage.Get()
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	// Atur konteks dengan timeout
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout(size))
+	defer cancel()
+
+	// Buka file yang diupload
+	src, err := open()
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("failed to open uploaded file: %v", err)
+	}
+	defer src.Close()
+
+	if info.Category == CategoryImage && ProcessableImage(info.ContentType) {
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return UploadedFile{}, fmt.Errorf("failed to read uploaded file: %v", err)
+		}
+		result, err := uploadImageVariants(ctx, store, folder, data)
+		result.Provider = store.Name()
+		return result, err
+	}
+
+	// GIF tidak di-encode ulang, tapi metadatanya tetap dibuang
+	var body io.Reader = src
+	if info.ContentType == "image/gif" {
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return UploadedFile{}, fmt.Errorf("failed to read uploaded file: %v", err)
+		}
+		if data, err = StripGIFMetadata(data); err != nil {
+			return UploadedFile{}, err
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	// Ekstensi diambil dari isi file, bukan dari nama file kiriman browser
+	name, err := RandomToken(16)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	object, err := store.Upload(ctx, storage.NewKey(folder, name, info.Extension), body, size, info.ContentType)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	return UploadedFile{Object: object, Provider: store.Name(), Category: info.Category}, nil
+}
+
+// uploadImageVariants memproses gambar lalu mengunggah varian full, medium & thumb.
+// Gambar asli (beserta EXIF/GPS-nya) tidak pernah disimpan.
+func uploadImageVariants(ctx context.Context, store storage.Storage, folder string, data []byte) (UploadedFile, error) {
+	variants, err := ProcessImage(data)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	name, err := RandomToken(16)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	base := storage.NewKey(folder, name, "")
+	result := UploadedFile{Category: CategoryImage}
+	var uploaded []storage.Object
+	for _, variant := range variants {
+		key := base + "_" + variant.Name + ".jpg"
+		object, err := store.Upload(ctx, key, bytes.NewReader(variant.Data), int64(len(variant.Data)), "image/jpeg")
+		if err != nil {
+			// Jangan tinggalkan varian setengah jadi di storage
+			for _, o := range uploaded {
+				if delErr := store.Delete(context.Background(), o.Key); delErr != nil {
+					log.Printf("Failed to clean up %s: %v", o.Key, delErr)
+				}
+			}
+			return UploadedFile{}, err
+		}
+		uploaded = append(uploaded, object)
+
+		switch variant.Name {
+		case VariantFull:
+			result.Object = object
+			result.Width, result.Height = variant.Width, variant.Height
+		case VariantMedium:
+			result.Medium = &object
+		case VariantThumb:
+			result.Thumb = &object
+		}
+	}
+	return result, nil
+}
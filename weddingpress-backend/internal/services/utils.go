@@ -1,6 +1,9 @@
 package services
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"math/rand"
 	"regexp"
 	"strings"
@@ -33,3 +36,13 @@ func RandomString(length int) string {
 	}
 	return string(b)
 }
+
+// RandomToken membuat token acak yang aman secara kriptografis (hex, panjang 2*bytes)
+// Gunakan ini untuk token rahasia (misal: URL feed) & nama file, bukan RandomString.
+func RandomToken(bytes int) (string, error) {
+	b := make([]byte, bytes)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
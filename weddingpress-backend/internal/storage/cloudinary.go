@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// CloudinaryStorage menyimpan file di Cloudinary. Client dibuat sekali saat startup.
+type CloudinaryStorage struct {
+	cld *cloudinary.Cloudinary
+}
+
+// NewCloudinaryStorage membuat storage Cloudinary dari CLOUDINARY_URL
+func NewCloudinaryStorage(cldURL string) (*CloudinaryStorage, error) {
+	if cldURL == "" {
+		return nil, errors.New("CLOUDINARY_URL not found in environment")
+	}
+	cld, err := cloudinary.NewFromURL(cldURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Cloudinary: %v", err)
+	}
+	return &CloudinaryStorage{cld: cld}, nil
+}
+
+func (s *CloudinaryStorage) Name() string { return "cloudinary" }
+
+// cloudinaryPublicID: Cloudinary menyimpan public ID tanpa ekstensi untuk gambar/video
+func cloudinaryPublicID(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key))
+}
+
+// cloudinaryResourceType: Cloudinary menyimpan audio sebagai resource "video"
+func cloudinaryResourceType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "image"
+	case strings.HasPrefix(contentType, "video/"), strings.HasPrefix(contentType, "audio/"):
+		return "video"
+	case contentType == "":
+		return "auto"
+	}
+	return "raw"
+}
+
+func (s *CloudinaryStorage) Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) (Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return Object{}, err
+	}
+
+	overwrite := false
+	result, err := s.cld.Upload.Upload(ctx, r, uploader.UploadParams{
+		PublicID:     cloudinaryPublicID(key),
+		Overwrite:    &overwrite,
+		ResourceType: cloudinaryResourceType(contentType),
+	})
+	if err != nil {
+		return Object{}, fmt.Errorf("failed to upload file: %v", err)
+	}
+	if result.Error.Message != "" {
+		return Object{}, fmt.Errorf("failed to upload file: %s", result.Error.Message)
+	}
+
+	return Object{
+		Key:         key,
+		URL:         result.SecureURL,
+		Size:        int64(result.Bytes),
+		ContentType: contentType,
+	}, nil
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	publicID := cloudinaryPublicID(key)
+	// Tipe resource tidak disimpan di key; coba semua tipe sampai ada yang terhapus
+	for _, resourceType := range []string{"image", "video", "raw"} {
+		id := publicID
+		if resourceType == "raw" {
+			id = key // File raw menyimpan ekstensi di public ID
+		}
+		result, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+			PublicID:     id,
+			ResourceType: resourceType,
+			Invalidate:   api.Bool(true),
+		})
+		if err != nil {
+			return err
+		}
+		if result.Result == "ok" {
+			return nil
+		}
+	}
+	return nil // "not found" di semua tipe: anggap sudah terhapus
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage menyimpan file di disk; file disajikan Gin lewat r.Static(URLPath, Dir)
+type LocalStorage struct {
+	Dir     string // Folder di disk, misal "./uploads"
+	URLPath string // Path route statis, misal "/uploads"
+	BaseURL string // URL publik server, misal "http://localhost:8080"
+}
+
+// NewLocalStorageFromEnv membaca LOCAL_STORAGE_DIR (default "./uploads") dan
+// PUBLIC_API_URL (default "http://localhost:<SERVER_PORT>")
+func NewLocalStorageFromEnv() (*LocalStorage, error) {
+	dir := os.Getenv("LOCAL_STORAGE_DIR")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	baseURL := os.Getenv("PUBLIC_API_URL")
+	if baseURL == "" {
+		port := os.Getenv("SERVER_PORT")
+		if port == "" {
+			port = "8080"
+		}
+		baseURL = "http://localhost:" + port
+	}
+	return NewLocalStorage(dir, "/uploads", baseURL)
+}
+
+// NewLocalStorage membuat storage lokal dan memastikan foldernya ada
+func NewLocalStorage(dir, urlPath, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{
+		Dir:     dir,
+		URLPath: "/" + strings.Trim(urlPath, "/"),
+		BaseURL: strings.TrimRight(baseURL, "/"),
+	}, nil
+}
+
+func (s *LocalStorage) Name() string { return "local" }
+
+func (s *LocalStorage) Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) (Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return Object{}, err
+	}
+
+	fullPath := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return Object{}, err
+	}
+
+	// Tulis ke file sementara lalu rename, agar file setengah jadi tidak pernah tersaji
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return Object{}, err
+	}
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), fullPath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return Object{}, err
+	}
+
+	return Object{
+		Key:         key,
+		URL:         s.BaseURL + s.URLPath + "/" + key,
+		Size:        written,
+		ContentType: contentType,
+	}, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage menyimpan file di bucket S3-compatible (AWS S3, MinIO, R2, dll)
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string // URL dasar objek, misal "https://cdn.example.com" atau "http://localhost:9000/bucket"
+}
+
+// NewS3StorageFromEnv membaca S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY, S3_BUCKET,
+// S3_REGION, S3_USE_SSL (default true) dan S3_PUBLIC_URL (opsional)
+func NewS3StorageFromEnv() (*S3Storage, error) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	bucket := os.Getenv("S3_BUCKET")
+	if endpoint == "" || bucket == "" {
+		return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required for the s3 storage driver")
+	}
+	useSSL := os.Getenv("S3_USE_SSL") != "false"
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(os.Getenv("S3_ACCESS_KEY"), os.Getenv("S3_SECRET_KEY"), ""),
+		Secure: useSSL,
+		Region: os.Getenv("S3_REGION"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize S3 client: %v", err)
+	}
+
+	publicURL := os.Getenv("S3_PUBLIC_URL")
+	if publicURL == "" {
+		scheme := "https"
+		if !useSSL {
+			scheme = "http"
+		}
+		publicURL = scheme + "://" + endpoint + "/" + bucket // Path-style
+	}
+
+	return &S3Storage{client: client, bucket: bucket, publicURL: strings.TrimRight(publicURL, "/")}, nil
+}
+
+func (s *S3Storage) Name() string { return "s3" }
+
+func (s *S3Storage) objectURL(key string) string {
+	escaped := make([]string, 0)
+	for _, part := range strings.Split(key, "/") {
+		escaped = append(escaped, url.PathEscape(part))
+	}
+	return s.publicURL + "/" + strings.Join(escaped, "/")
+}
+
+func (s *S3Storage) Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) (Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return Object{}, err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	info, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable", // Key selalu unik
+	})
+	if err != nil {
+		return Object{}, fmt.Errorf("failed to upload file: %v", err)
+	}
+
+	return Object{
+		Key:         key,
+		URL:         s.objectURL(key),
+		Size:        info.Size,
+		ContentType: contentType,
+	}, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
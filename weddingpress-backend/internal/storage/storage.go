@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Object adalah file yang sudah tersimpan di storage
+type Object struct {
+	Key         string // Path di storage, misal "weddingpress/wedding-1/ab12cd.jpg"
+	URL         string // URL publik untuk diakses browser
+	Size        int64
+	ContentType string
+}
+
+// Storage adalah backend penyimpanan file upload (Cloudinary, disk lokal, S3/MinIO)
+type Storage interface {
+	// Name mengembalikan nama driver ("cloudinary", "local", "s3")
+	Name() string
+	// Upload menyimpan isi r dengan key tertentu dan mengembalikan objek beserta URL publiknya
+	Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) (Object, error)
+	// Delete menghapus objek; key yang tidak ada tidak dianggap error
+	Delete(ctx context.Context, key string) error
+}
+
+// Active adalah storage yang dipakai aplikasi, diisi oleh Init saat startup
+var Active Storage
+
+// Init memilih driver storage dari STORAGE_DRIVER (cloudinary, local, s3). Driver wajib
+// diisi: tanpa itu server tidak jalan, daripada diam-diam menyimpan file di disk lokal.
+func Init() error {
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_DRIVER")))
+
+	var err error
+	switch driver {
+	case "":
+		return errors.New("STORAGE_DRIVER is not set (use cloudinary, local or s3)")
+	case "cloudinary":
+		Active, err = NewCloudinaryStorage(os.Getenv("CLOUDINARY_URL"))
+	case "local":
+		Active, err = NewLocalStorageFromEnv()
+	case "s3", "minio":
+		Active, err = NewS3StorageFromEnv()
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q (use cloudinary, local or s3)", driver)
+	}
+	return err
+}
+
+// Get mengembalikan storage aktif, atau error jika Init belum dipanggil
+func Get() (Storage, error) {
+	if Active == nil {
+		return nil, errors.New("storage is not configured")
+	}
+	return Active, nil
+}
+
+// NewKey membuat key di dalam folder dari nama unik & ekstensi tertentu (misal ".jpg", boleh kosong)
+func NewKey(folder, name, ext string) string {
+	ext = strings.ToLower(ext)
+	if len(ext) > 10 || !strings.HasPrefix(ext, ".") || strings.ContainsAny(ext, `/\ `) {
+		ext = ""
+	}
+	return path.Join(folder, name+ext)
+}
+
+// cleanKey menolak key yang keluar dari folder (misal "../")
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return cleaned, nil
+}
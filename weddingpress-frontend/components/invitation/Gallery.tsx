@@ -1,6 +1,12 @@
-import { Gallery } from "@/types/models";
+import { Gallery, Wedding } from "@/types/models";
 import Image from "next/image";
 
+// galleryItems menggabungkan item per album (sesuai urutan album) dengan item tanpa album
+export function galleryItems(wedding: Wedding): Gallery[] {
+  const albumItems = (wedding.gallery_albums ?? []).flatMap((album) => album.items ?? []);
+  return [...albumItems, ...(wedding.galleries ?? [])];
+}
+
 export function GallerySection({ data }: { data: Gallery[] }) {
   if (!data || data.length === 0) return null;
   return (
@@ -17,7 +17,15 @@ export function StorySection({ data }: { data: Story[] }) {
             <div className="w-5/12 p-4 bg-white rounded-lg shadow-md">
               <h3 className="text-xl font-semibold" style={{ color: "var(--theme-color)" }}>{story.title}</h3>
               <span className="text-sm text-gray-500">{format(new Date(story.date), "dd MMMM yyyy")}</span>
-              <p className="mt-2 text-gray-600">{story.description}</p>
+              {/* description_html dirender & disanitasi backend (HTML mentah dibuang, link berbahaya dinetralkan) */}
+              {story.description_html ? (
+                <div
+                  className="mt-2 text-gray-600 space-y-2 [&_a]:underline [&_img]:rounded-md"
+                  dangerouslySetInnerHTML={{ __html: story.description_html }}
+                />
+              ) : (
+                <p className="mt-2 text-gray-600 whitespace-pre-line">{story.description}</p>
+              )}
             </div>
             {/* Titik di Timeline */}
             <div className="absolute left-1/2 top-1/2 w-4 h-4 rounded-full bg-white border-2 -translate-x-1/2 -translate-y-1/2" style={{ borderColor: "var(--theme-color)" }}></div>
@@ -7,7 +7,7 @@ import { AudioPlayer } from "@/components/invitation/AudioPlayer";
 import { GroomBrideSection } from "@/components/invitation/GroomBride";
 import { EventsSection } from "@/components/invitation/Events";
 import { StorySection } from "@/components/invitation/Story";
-import { GallerySection } from "@/components/invitation/Gallery";
+import { GallerySection, galleryItems } from "@/components/invitation/Gallery";
 import { GiftSection } from "@/components/invitation/GiftSection";
 import { motion, AnimatePresence } from "framer-motion";
 import { BookOpen } from "lucide-react";
@@ -52,7 +52,7 @@ export function ClassicTemplate({ data }: { data: InvitationData }) {
                <div className="my-8 border-t border-stone-200 w-1/2 mx-auto"></div>
 
                {wedding.show_story && <StorySection data={wedding.stories} />}
-               {wedding.show_gallery && <GallerySection data={wedding.galleries} />}
+               {wedding.show_gallery && <GallerySection data={galleryItems(wedding)} />}
                
                {wedding.show_gifts && wedding.gift_accounts && wedding.gift_accounts.length > 0 && (
                   <div className="bg-stone-50 p-8 rounded-lg border border-stone-200 mx-4 my-8">
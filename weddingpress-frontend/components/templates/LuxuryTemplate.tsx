@@ -7,7 +7,7 @@ import { AudioPlayer } from "@/components/invitation/AudioPlayer";
 import { GroomBrideSection } from "@/components/invitation/GroomBride";
 import { EventsSection } from "@/components/invitation/Events";
 import { StorySection } from "@/components/invitation/Story";
-import { GallerySection } from "@/components/invitation/Gallery";
+import { GallerySection, galleryItems } from "@/components/invitation/Gallery";
 import { GiftSection } from "@/components/invitation/GiftSection";
 import { RSVPForm } from "@/components/invitation/RSVPForm";
 import { Guestbook } from "@/components/invitation/GuestBook";
@@ -78,7 +78,7 @@ export function LuxuryTemplate({ data }: { data: InvitationData }) {
                     </div>
                 )}
                 
-                {wedding.show_gallery && <GallerySection data={wedding.galleries} />}
+                {wedding.show_gallery && <GallerySection data={galleryItems(wedding)} />}
                 
                 {wedding.show_gifts && wedding.gift_accounts?.length > 0 && (
                      <div className="bg-[#0F172A] p-8 rounded-xl border border-[#BF953F]/50 [&_p]:text-slate-300 [&_h3]:text-[#FCF6BA]">
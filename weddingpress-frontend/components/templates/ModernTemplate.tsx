@@ -7,7 +7,7 @@ import { AudioPlayer } from "@/components/invitation/AudioPlayer";
 import { GroomBrideSection } from "@/components/invitation/GroomBride";
 import { EventsSection } from "@/components/invitation/Events";
 import { StorySection } from "@/components/invitation/Story";
-import { GallerySection } from "@/components/invitation/Gallery";
+import { GallerySection, galleryItems } from "@/components/invitation/Gallery";
 import { GiftSection } from "@/components/invitation/GiftSection";
 import { motion, AnimatePresence } from "framer-motion";
 import { Mail } from "lucide-react";
@@ -37,7 +37,7 @@ export function ModernTemplate({ data }: { data: InvitationData }) {
 
             {wedding.show_events && <EventsSection data={wedding.events} />}
             {wedding.show_story && <StorySection data={wedding.stories} />}
-            {wedding.show_gallery && <GallerySection data={wedding.galleries} />}
+            {wedding.show_gallery && <GallerySection data={galleryItems(wedding)} />}
             {wedding.show_gifts && wedding.gift_accounts && wedding.gift_accounts.length > 0 && (
               <GiftSection accounts={wedding.gift_accounts} />
             )}
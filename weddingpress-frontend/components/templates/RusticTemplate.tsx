@@ -7,7 +7,7 @@ import { AudioPlayer } from "@/components/invitation/AudioPlayer";
 import { GroomBrideSection } from "@/components/invitation/GroomBride";
 import { EventsSection } from "@/components/invitation/Events";
 import { StorySection } from "@/components/invitation/Story";
-import { GallerySection } from "@/components/invitation/Gallery";
+import { GallerySection, galleryItems } from "@/components/invitation/Gallery";
 import { GiftSection } from "@/components/invitation/GiftSection";
 import { RSVPForm } from "@/components/invitation/RSVPForm";
 import { Guestbook } from "@/components/invitation/GuestBook";
@@ -70,7 +70,7 @@ export function RusticTemplate({ data }: { data: InvitationData }) {
                 
                 {wedding.show_gallery && (
                     <div className="p-4 bg-white shadow-lg rotate-1 rounded-lg">
-                        <GallerySection data={wedding.galleries} />
+                        <GallerySection data={galleryItems(wedding)} />
                     </div>
                 )}
 
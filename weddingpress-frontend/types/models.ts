@@ -33,7 +33,8 @@ export interface User {
     groom_bride: GroomBride; // Relasi Has One
     events: Event[]; // Relasi Has Many
     stories: Story[]; // Relasi Has Many
-    galleries: Gallery[]; // Relasi Has Many
+    galleries: Gallery[]; // Item galeri tanpa album (di undangan publik)
+    gallery_albums: GalleryAlbum[]; // Album beserta itemnya
     guests: Guest[]; // Relasi Has Many
     gift_accounts: GiftAccount[]; // <-- TAMBAHKAN INI
     // --- TAMBAHKAN FIELD KUSTOMISASI DI SINI ---
@@ -78,7 +79,8 @@ export interface User {
     wedding_id: number;
     title: string;
     date: string; // Tipe time.Time menjadi string di JSON
-    description: string;
+    description: string; // Markdown mentah
+    description_html?: string; // Hasil render Markdown (hanya di undangan publik)
     order: number;
   }
   
@@ -89,6 +91,19 @@ export interface User {
     file_url: string;
     file_type: "image" | "video"; // Tipe "image" atau "video"
     caption: string;
+    album_id: number | null; // null = tanpa album
+    order: number;
+  }
+
+  // GalleryAlbum mengelompokkan item galeri
+  export interface GalleryAlbum {
+    id: number;
+    wedding_id: number;
+    name: string;
+    description: string;
+    order: number;
+    items: Gallery[];
+    cover_url: string;
   }
   
   // Guest adalah tamu undangan
@@ -100,7 +115,7 @@ export interface User {
     group: string;
     is_rsvp: boolean;
     total_attendance: number;
-    guest_book: GuestBook; // Relasi Has One
+    guest_books: GuestBook[]; // Relasi Has Many (satu tamu bisa beberapa ucapan)
     created_at: string;
     updated_at: string;
   }
@@ -110,8 +125,22 @@ export interface User {
     id: number;
     guest_id: number;
     message: string;
-    status: "pending" | "approved"; // "pending" atau "approved"
+    status: "pending" | "approved" | "rejected" | "hidden";
+    flagged: boolean; // Mengandung kata terlarang
+    flagged_words: string;
+    moderated_at: string | null;
     created_at: string;
+    replies?: GuestBookReply[]; // Balasan mempelai
+  }
+
+  // GuestBookReply adalah balasan mempelai untuk satu ucapan
+  export interface GuestBookReply {
+    id: number;
+    guest_book_id: number;
+    user_id: number | null;
+    message: string;
+    created_at: string;
+    updated_at: string;
   }
   
   // =======================================================